@@ -0,0 +1,131 @@
+// Command provision creates what the bank sets up for its customers and staff
+// outside of the API: users whose role cannot be registered through it, such
+// as the Admin of a corporate, and the accounts each corporate holds.
+//
+//	provision user -role Admin -account-number 1234567890 -account-name "PT Example" \
+//		-user-id admin01 -user-name "Example Admin" -phone-number +628123456789 -email admin@example.com
+//	provision account -owner 1234567890 -account 9876543210
+//
+// The password of a new user is read from the PROVISION_PASSWORD environment
+// variable, so it does not end up in the shell history.
+package main
+
+import (
+	"batch-transaction/internal/config"
+	"batch-transaction/internal/database"
+	"batch-transaction/internal/domain"
+	"batch-transaction/internal/repository"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func main() {
+	// the environment may come from the container instead of a .env file
+	godotenv.Load()
+
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	config := config.NewConfig()
+	db, err := database.NewDB("postgres", config.GetDatabaseURL(), log.Default())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := context.Background()
+	switch os.Args[1] {
+	case "user":
+		err = provisionUser(ctx, repository.NewUserRepository(db), os.Args[2:])
+	case "account":
+		err = provisionAccount(ctx, repository.NewLinkedAccountRepository(db), os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: provision user|account [flags]")
+	os.Exit(2)
+}
+
+func provisionUser(ctx context.Context, userRepo *repository.UserRepository, args []string) error {
+	flags := flag.NewFlagSet("user", flag.ExitOnError)
+	role := flags.String("role", "", "Maker, Approver, Admin, Compliance or Operator")
+	accountNumber := flags.String("account-number", "", "corporate account number, or the bank's own for bank staff")
+	accountName := flags.String("account-name", "", "name of the corporate account")
+	userID := flags.String("user-id", "", "user id used to sign in")
+	userName := flags.String("user-name", "", "full name of the user")
+	phoneNumber := flags.String("phone-number", "", "phone number in E.164 format")
+	email := flags.String("email", "", "email address")
+	flags.Parse(args)
+
+	user := domain.User{
+		AccountNumber: strings.TrimSpace(*accountNumber),
+		AccountName:   strings.TrimSpace(*accountName),
+		UserID:        strings.TrimSpace(*userID),
+		UserName:      strings.TrimSpace(*userName),
+		Role:          domain.Role(*role),
+		PhoneNumber:   strings.TrimSpace(*phoneNumber),
+		Email:         strings.TrimSpace(*email),
+	}
+	switch user.Role {
+	case domain.Maker, domain.Approver, domain.Admin, domain.Compliance, domain.Operator:
+	default:
+		return fmt.Errorf("unknown role %q", *role)
+	}
+	if user.AccountNumber == "" || user.AccountName == "" || user.UserID == "" || user.UserName == "" || user.PhoneNumber == "" || user.Email == "" {
+		return fmt.Errorf("account-number, account-name, user-id, user-name, phone-number and email are required")
+	}
+
+	password := os.Getenv("PROVISION_PASSWORD")
+	if password == "" {
+		return fmt.Errorf("PROVISION_PASSWORD is not set")
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	user.Password = string(hashedPassword)
+
+	err = userRepo.CreateUser(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("created %s user %s for account %s", user.Role, user.UserID, user.AccountNumber)
+	return nil
+}
+
+func provisionAccount(ctx context.Context, linkedAccountRepo *repository.LinkedAccountRepository, args []string) error {
+	flags := flag.NewFlagSet("account", flag.ExitOnError)
+	owner := flags.String("owner", "", "account number of the corporate holding the account")
+	account := flags.String("account", "", "account number held by the corporate")
+	flags.Parse(args)
+
+	corporateAccount := domain.CorporateAccount{
+		AccountNumber: strings.TrimSpace(*account),
+		Owner:         strings.TrimSpace(*owner),
+	}
+	if corporateAccount.AccountNumber == "" || corporateAccount.Owner == "" {
+		return fmt.Errorf("owner and account are required")
+	}
+
+	err := linkedAccountRepo.SaveCorporateAccount(ctx, corporateAccount)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("recorded account %s as held by %s", corporateAccount.AccountNumber, corporateAccount.Owner)
+	return nil
+}
@@ -14,8 +14,9 @@ type JWTService interface {
 }
 
 type JWTClaim struct {
-	UserID string
-	Role   string
+	UserID        string
+	Role          string
+	AccountNumber string
 	jwt.Claims
 }
 
@@ -28,9 +29,10 @@ func NewJWTServiceImpl() *JWTServiceImpl {
 func (j *JWTServiceImpl) SignJWT(user domain.User, secretKey []byte) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256,
 		jwt.MapClaims{
-			"userid": user.UserID,
-			"role":   user.Role,
-			"exp":    time.Now().Add(time.Hour * 24).Unix(),
+			"userid":         user.UserID,
+			"role":           user.Role,
+			"account_number": user.AccountNumber,
+			"exp":            time.Now().Add(time.Hour * 24).Unix(),
 		})
 
 	return token.SignedString(secretKey)
@@ -48,7 +50,12 @@ func (j *JWTServiceImpl) VerifyJWT(tokenString string, secretKey string) (JWTCla
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
 		userID := claims["userid"].(string)
 		role := claims["role"].(string)
-		return JWTClaim{UserID: userID, Role: role, Claims: claims}, nil
+		// tokens issued before corporate scoping carry no account number
+		accountNumber, ok := claims["account_number"].(string)
+		if !ok || accountNumber == "" {
+			return JWTClaim{}, domain.ErrInvalidToken
+		}
+		return JWTClaim{UserID: userID, Role: role, AccountNumber: accountNumber, Claims: claims}, nil
 	} else {
 		return JWTClaim{}, err
 	}
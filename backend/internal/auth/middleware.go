@@ -31,6 +31,7 @@ func BearerAuthMiddleware(jwtSigner JWTService, secretKey string) func(next http
 
 			ctx := context.WithValue(r.Context(), "user_id", claims.UserID)
 			ctx = context.WithValue(ctx, "role", claims.Role)
+			ctx = context.WithValue(ctx, "account_number", claims.AccountNumber)
 
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
@@ -1,10 +1,47 @@
 package config
 
-import "os"
+import (
+	"batch-transaction/internal/domain"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
 
 type ConfigInterface interface {
 	GetSecretKey() string
 	GetDatabaseURL() string
+	GetCSVColumnAliases() map[string][]string
+	GetFixedWidthLayout() string
+	GetIdempotencyKeyTTL() time.Duration
+	GetIdempotencyPendingTTL() time.Duration
+	GetDuplicateCheckWindow() time.Duration
+	GetMixedCurrencyEnabled() bool
+	GetTransferCalendar() domain.TransferCalendar
+	GetSchedulerInterval() time.Duration
+	GetExecutionWorkers() int
+	GetBankDirectoryFile() string
+	GetInquiryInterval() time.Duration
+	GetInquiryBlockMismatch() bool
+	GetWatchlistFile() string
+	GetScreeningThreshold() float64
+	GetApprovalOTPTTL() time.Duration
+	GetApprovalOTPMinAmounts() map[string]domain.Money
+	GetOTPTTL() time.Duration
+	GetOTPMaxAttempts() int
+	GetOTPLockoutDuration() time.Duration
+	GetOTPSendWindow() time.Duration
+	GetOTPSendLimitPerEmail() int
+	GetOTPSendLimitPerIP() int
+	GetOTPChannel() string
+	GetOTPDevMode() bool
+	GetOTPLogFile() string
+	GetSMTPAddr() string
+	GetSMTPUsername() string
+	GetSMTPPassword() string
+	GetSMTPFrom() string
+	GetSMSGatewayURL() string
+	GetSMSGatewayAPIKey() string
 }
 
 type Config struct{}
@@ -20,3 +57,299 @@ func (c *Config) GetSecretKey() string {
 func (c *Config) GetDatabaseURL() string {
 	return os.Getenv("DATABASE_URL")
 }
+
+// GetCSVColumnAliases reads extra header names for upload columns, formatted
+// as "column=alias1,alias2;column2=alias3".
+func (c *Config) GetCSVColumnAliases() map[string][]string {
+	aliases := map[string][]string{}
+	for _, entry := range strings.Split(os.Getenv("CSV_COLUMN_ALIASES"), ";") {
+		column, names, found := strings.Cut(entry, "=")
+		if !found {
+			continue
+		}
+		column = strings.TrimSpace(column)
+		for _, name := range strings.Split(names, ",") {
+			name = strings.TrimSpace(name)
+			if name != "" {
+				aliases[column] = append(aliases[column], name)
+			}
+		}
+	}
+	return aliases
+}
+
+// DefaultFixedWidthLayout is the fixed-width layout used when
+// FIXED_WIDTH_LAYOUT is not set.
+const DefaultFixedWidthLayout = "bank_dest:1:10,account_id_dest:11:20,account_name_dest:31:40,amount:71:15"
+
+// GetFixedWidthLayout returns the column layout used for fixed-width uploads,
+// formatted as "column:start:length,...", defaulting to
+// DefaultFixedWidthLayout.
+func (c *Config) GetFixedWidthLayout() string {
+	layout := os.Getenv("FIXED_WIDTH_LAYOUT")
+	if strings.TrimSpace(layout) == "" {
+		return DefaultFixedWidthLayout
+	}
+	return layout
+}
+
+// GetIdempotencyKeyTTL returns how long an Idempotency-Key is remembered,
+// defaulting to 24 hours.
+func (c *Config) GetIdempotencyKeyTTL() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv("IDEMPOTENCY_KEY_TTL"))
+	if err != nil || ttl <= 0 {
+		return 24 * time.Hour
+	}
+	return ttl
+}
+
+// GetIdempotencyPendingTTL returns how long an Idempotency-Key stays reserved
+// for a request that is still running, defaulting to 5 minutes.
+func (c *Config) GetIdempotencyPendingTTL() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv("IDEMPOTENCY_PENDING_TTL"))
+	if err != nil || ttl <= 0 {
+		return 5 * time.Minute
+	}
+	return ttl
+}
+
+// GetDuplicateCheckWindow returns how far back uploads are compared when
+// looking for duplicate batches, defaulting to 24 hours.
+func (c *Config) GetDuplicateCheckWindow() time.Duration {
+	window, err := time.ParseDuration(os.Getenv("DUPLICATE_CHECK_WINDOW"))
+	if err != nil || window <= 0 {
+		return 24 * time.Hour
+	}
+	return window
+}
+
+// GetMixedCurrencyEnabled reports whether makers may upload batches with rows
+// in more than one currency.
+func (c *Config) GetMixedCurrencyEnabled() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("MIXED_CURRENCY_ENABLED"))
+	return enabled
+}
+
+// GetTransferCalendar builds the calendar transfer dates are checked against
+// from TRANSFER_CUTOFF_TIME ("15:00" by default), TRANSFER_HOLIDAYS (a comma
+// separated list of YYYY-MM-DD dates) and TRANSFER_TIMEZONE ("Asia/Jakarta" by
+// default).
+func (c *Config) GetTransferCalendar() domain.TransferCalendar {
+	cutOff := 15 * time.Hour
+	if parsed, err := time.Parse("15:04", os.Getenv("TRANSFER_CUTOFF_TIME")); err == nil {
+		cutOff = time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute
+	}
+
+	var holidays []string
+	for _, holiday := range strings.Split(os.Getenv("TRANSFER_HOLIDAYS"), ",") {
+		holiday = strings.TrimSpace(holiday)
+		if _, err := time.Parse(domain.TransferDateLayout, holiday); err == nil {
+			holidays = append(holidays, holiday)
+		}
+	}
+
+	timezone := os.Getenv("TRANSFER_TIMEZONE")
+	if timezone == "" {
+		timezone = "Asia/Jakarta"
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		location = time.FixedZone("WIB", 7*60*60)
+	}
+
+	return domain.NewTransferCalendar(cutOff, holidays, location)
+}
+
+// GetSchedulerInterval returns how often scheduled batches are checked,
+// defaulting to one minute.
+func (c *Config) GetSchedulerInterval() time.Duration {
+	interval, err := time.ParseDuration(os.Getenv("SCHEDULER_INTERVAL"))
+	if err != nil || interval <= 0 {
+		return time.Minute
+	}
+	return interval
+}
+
+// GetExecutionWorkers returns how many transfers are executed at the same
+// time, defaulting to 4.
+func (c *Config) GetExecutionWorkers() int {
+	workers, err := strconv.Atoi(os.Getenv("EXECUTION_WORKERS"))
+	if err != nil || workers <= 0 {
+		return 4
+	}
+	return workers
+}
+
+// GetBankDirectoryFile returns the JSON file the bank directory is seeded
+// from, defaulting to banks.json.
+func (c *Config) GetBankDirectoryFile() string {
+	path := os.Getenv("BANK_DIRECTORY_FILE")
+	if path == "" {
+		return "banks.json"
+	}
+	return path
+}
+
+// GetInquiryInterval returns how often rows waiting for an account name
+// inquiry are picked up, defaulting to 5 seconds.
+func (c *Config) GetInquiryInterval() time.Duration {
+	interval, err := time.ParseDuration(os.Getenv("INQUIRY_INTERVAL"))
+	if err != nil || interval <= 0 {
+		return 5 * time.Second
+	}
+	return interval
+}
+
+// GetInquiryBlockMismatch reports whether batches can only be approved once
+// every account name inquiry is done and none of them is a mismatch.
+func (c *Config) GetInquiryBlockMismatch() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("INQUIRY_BLOCK_MISMATCH"))
+	return enabled
+}
+
+// GetWatchlistFile returns the JSON or CSV watchlist beneficiaries are screened
+// against, defaulting to watchlist.json.
+func (c *Config) GetWatchlistFile() string {
+	path := os.Getenv("WATCHLIST_FILE")
+	if path == "" {
+		return "watchlist.json"
+	}
+	return path
+}
+
+// GetScreeningThreshold returns the name similarity, between 0 and 1, from
+// which a beneficiary matches a watchlist entry, defaulting to 0.85.
+func (c *Config) GetScreeningThreshold() float64 {
+	threshold, err := strconv.ParseFloat(os.Getenv("SCREENING_THRESHOLD"), 64)
+	if err != nil || threshold <= 0 || threshold > 1 {
+		return 0.85
+	}
+	return threshold
+}
+
+// GetApprovalOTPTTL returns how long an approval OTP stays valid, defaulting
+// to two minutes.
+func (c *Config) GetApprovalOTPTTL() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv("APPROVAL_OTP_TTL"))
+	if err != nil || ttl <= 0 {
+		return 2 * time.Minute
+	}
+	return ttl
+}
+
+// GetApprovalOTPMinAmounts returns per currency the total from which
+// approving a batch needs an OTP, formatted as "IDR:100000000,USD:5000".
+// Currencies that are not listed, or an invalid value, have no threshold so
+// every approval needs an OTP.
+func (c *Config) GetApprovalOTPMinAmounts() map[string]domain.Money {
+	amounts, err := domain.ParseCurrencyTotals(os.Getenv("APPROVAL_OTP_MIN_AMOUNTS"))
+	if err != nil {
+		return map[string]domain.Money{}
+	}
+	return amounts
+}
+
+// GetOTPTTL returns how long a registration OTP stays valid, defaulting to
+// five minutes.
+func (c *Config) GetOTPTTL() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv("OTP_TTL"))
+	if err != nil || ttl <= 0 {
+		return 5 * time.Minute
+	}
+	return ttl
+}
+
+// GetOTPMaxAttempts returns how many wrong OTPs an email may send before it is
+// locked, defaulting to 5.
+func (c *Config) GetOTPMaxAttempts() int {
+	attempts, err := strconv.Atoi(os.Getenv("OTP_MAX_ATTEMPTS"))
+	if err != nil || attempts <= 0 {
+		return 5
+	}
+	return attempts
+}
+
+// GetOTPLockoutDuration returns how long an email stays locked after too many
+// wrong OTPs, defaulting to 15 minutes. Wrong attempts are counted over the
+// same period.
+func (c *Config) GetOTPLockoutDuration() time.Duration {
+	duration, err := time.ParseDuration(os.Getenv("OTP_LOCKOUT_DURATION"))
+	if err != nil || duration <= 0 {
+		return 15 * time.Minute
+	}
+	return duration
+}
+
+// GetOTPSendWindow returns the period OTP requests are counted over for rate
+// limiting, defaulting to 10 minutes.
+func (c *Config) GetOTPSendWindow() time.Duration {
+	window, err := time.ParseDuration(os.Getenv("OTP_SEND_WINDOW"))
+	if err != nil || window <= 0 {
+		return 10 * time.Minute
+	}
+	return window
+}
+
+// GetOTPSendLimitPerEmail returns how many OTPs may be requested for an email
+// within the send window, defaulting to 3.
+func (c *Config) GetOTPSendLimitPerEmail() int {
+	limit, err := strconv.Atoi(os.Getenv("OTP_SEND_LIMIT_PER_EMAIL"))
+	if err != nil || limit <= 0 {
+		return 3
+	}
+	return limit
+}
+
+// GetOTPSendLimitPerIP returns how many OTPs a client IP may request within
+// the send window, defaulting to 10.
+func (c *Config) GetOTPSendLimitPerIP() int {
+	limit, err := strconv.Atoi(os.Getenv("OTP_SEND_LIMIT_PER_IP"))
+	if err != nil || limit <= 0 {
+		return 10
+	}
+	return limit
+}
+
+// GetOTPChannel returns how one-time codes are delivered: "email", "sms" or
+// "log". It has no default, so a deployment cannot leak codes to its log by
+// forgetting to configure it.
+func (c *Config) GetOTPChannel() string {
+	return strings.ToLower(strings.TrimSpace(os.Getenv("OTP_CHANNEL")))
+}
+
+// GetOTPDevMode reports whether one-time codes are also returned in API
+// responses, which must only be enabled for local development.
+func (c *Config) GetOTPDevMode() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("OTP_DEV_MODE"))
+	return enabled
+}
+
+// GetOTPLogFile returns the file the "log" channel appends codes to, empty to
+// write them to the standard log.
+func (c *Config) GetOTPLogFile() string {
+	return os.Getenv("OTP_LOG_FILE")
+}
+
+func (c *Config) GetSMTPAddr() string {
+	return os.Getenv("SMTP_ADDR")
+}
+
+func (c *Config) GetSMTPUsername() string {
+	return os.Getenv("SMTP_USERNAME")
+}
+
+func (c *Config) GetSMTPPassword() string {
+	return os.Getenv("SMTP_PASSWORD")
+}
+
+func (c *Config) GetSMTPFrom() string {
+	return os.Getenv("SMTP_FROM")
+}
+
+func (c *Config) GetSMSGatewayURL() string {
+	return os.Getenv("SMS_GATEWAY_URL")
+}
+
+func (c *Config) GetSMSGatewayAPIKey() string {
+	return os.Getenv("SMS_GATEWAY_API_KEY")
+}
@@ -1,8 +1,8 @@
-CREATE TYPE user_role AS ENUM ('Maker', 'Approver');
+CREATE TYPE user_role AS ENUM ('Maker', 'Approver', 'Admin', 'Compliance', 'Operator');
 
 CREATE TABLE users (
 	id serial PRIMARY KEY,
-	account_number varchar(13) UNIQUE NOT NULL,
+	account_number varchar(13) NOT NULL,
 	account_name varchar(60) NOT NULL,
 	user_id varchar(13) UNIQUE NOT NULL,
 	user_name varchar(100) UNIQUE NOT NULL,
@@ -14,11 +14,16 @@ CREATE TABLE users (
     created_at timestamp NOT NULL DEFAULT NOW()
 );
 
-CREATE TYPE transaction_status AS ENUM ('waiting_approval','approved','rejected');
+CREATE TYPE transaction_status AS ENUM ('waiting_approval','approved','rejected','cancelled','processing','completed','failed','expired','partially_completed','compliance_review');
+CREATE TYPE detail_status AS ENUM ('pending','success','failed');
+CREATE TYPE inquiry_result AS ENUM ('pending','match','mismatch','unknown');
 CREATE TABLE transactions (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    account_number varchar(13) NOT NULL,
     total_amount DECIMAL(15,2) NOT NULL,
     total_record int NOT NULL,
+    currency char(3) NOT NULL DEFAULT 'IDR',
+    mixed_currency boolean NOT NULL DEFAULT false,
     from_account varchar(60) NOT NULL,
     maker varchar(60) NOT NULL,
     transfer_date timestamp,
@@ -27,9 +32,21 @@ CREATE TABLE transactions (
     -- account_name_dest varchar(60) NOT NULL,
     -- amount DECIMAL(15,2) NOT NULL,
     transaction_status transaction_status NOT NULL,
+    required_approvals int NOT NULL DEFAULT 1,
+    fingerprint varchar(64),
+    beneficiary_fingerprint varchar(64),
+    parent_transaction_id UUID REFERENCES transactions(id),
+    approved_at timestamp,
     created_at timestamp NOT NULL DEFAULT NOW()
 );
 
+CREATE INDEX idx_transactions_from_account_created_at ON transactions (from_account, created_at);
+CREATE INDEX idx_transactions_account_number_created_at ON transactions (account_number, created_at);
+CREATE INDEX idx_transactions_account_number_approved_at ON transactions (account_number, approved_at);
+-- a batch can only have one retry that is not rejected, cancelled or expired
+CREATE UNIQUE INDEX idx_transactions_active_retry ON transactions (parent_transaction_id)
+    WHERE transaction_status NOT IN ('rejected', 'cancelled', 'expired');
+
 CREATE TABLE transaction_details (
 	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
 	transaction_id UUID,
@@ -37,11 +54,144 @@ CREATE TABLE transaction_details (
     account_id_dest varchar(60) NOT NULL,
     account_name_dest varchar(60) NOT NULL,
     amount DECIMAL(15,2) NOT NULL,
+    currency char(3) NOT NULL DEFAULT 'IDR',
     description text,
     transfer_date timestamp,
+    status detail_status NOT NULL DEFAULT 'pending',
+    failure_reason text,
+    executed_at timestamp,
+    inquiry_result inquiry_result NOT NULL DEFAULT 'pending',
+    inquired_at timestamp,
     CONSTRAINT fk_transaction_details_transaction_id FOREIGN KEY (transaction_id)
     REFERENCES transactions(id)
 );
 
+CREATE INDEX idx_transaction_details_pending_inquiry ON transaction_details (transaction_id)
+    WHERE inquiry_result = 'pending';
+
+CREATE TABLE screening_hits (
+    id serial PRIMARY KEY,
+    transaction_id UUID NOT NULL REFERENCES transactions(id),
+    transaction_detail_id UUID NOT NULL REFERENCES transaction_details(id),
+    watchlist_name varchar(255) NOT NULL,
+    watchlist_account varchar(60) NOT NULL,
+    matched_on varchar(20) NOT NULL,
+    score DECIMAL(4,3) NOT NULL,
+    created_at timestamp NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_screening_hits_transaction_id ON screening_hits (transaction_id);
+
+CREATE TABLE approval_policies (
+    id serial PRIMARY KEY,
+    account_number varchar(13) NOT NULL,
+    currency char(3) NOT NULL DEFAULT 'IDR',
+    min_amount DECIMAL(15,2) NOT NULL,
+    required_approvals int NOT NULL CHECK (required_approvals > 0),
+    UNIQUE (account_number, currency, min_amount)
+);
+
+CREATE TABLE transaction_approvals (
+    id serial PRIMARY KEY,
+    transaction_id UUID NOT NULL,
+    approver varchar(60) NOT NULL,
+    approved_at timestamp NOT NULL DEFAULT NOW(),
+    UNIQUE (transaction_id, approver),
+    CONSTRAINT fk_transaction_approvals_transaction_id FOREIGN KEY (transaction_id)
+    REFERENCES transactions(id)
+);
 
- 
\ No newline at end of file
+CREATE TABLE transaction_events (
+    id serial PRIMARY KEY,
+    transaction_id UUID NOT NULL,
+    actor varchar(60) NOT NULL,
+    actor_role varchar(20) NOT NULL,
+    previous_status transaction_status,
+    new_status transaction_status NOT NULL,
+    reason text,
+    created_at timestamp NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_transaction_events_transaction_id FOREIGN KEY (transaction_id)
+    REFERENCES transactions(id)
+);
+
+-- transaction_events is an audit trail, rows may only be inserted
+CREATE FUNCTION prevent_transaction_events_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'transaction_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER transaction_events_append_only
+    BEFORE UPDATE OR DELETE ON transaction_events
+    FOR EACH ROW EXECUTE FUNCTION prevent_transaction_events_change();
+
+-- accounts held by each corporate, maintained by the bank through provisioning
+CREATE TABLE corporate_accounts (
+    account_number varchar(60) PRIMARY KEY,
+    owner varchar(13) NOT NULL,
+    created_at timestamp NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE linked_accounts (
+    id serial PRIMARY KEY,
+    account_number varchar(13) NOT NULL,
+    linked_account varchar(60) NOT NULL,
+    created_by varchar(60) NOT NULL,
+    created_at timestamp NOT NULL DEFAULT NOW(),
+    UNIQUE (account_number, linked_account)
+);
+
+CREATE TABLE beneficiaries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    account_number varchar(13) NOT NULL,
+    bank_dest varchar(60) NOT NULL,
+    account_id_dest varchar(60) NOT NULL,
+    account_name_dest varchar(60) NOT NULL,
+    currency char(3) NOT NULL DEFAULT 'IDR',
+    description text,
+    created_by varchar(60) NOT NULL,
+    created_at timestamp NOT NULL DEFAULT NOW(),
+    UNIQUE (account_number, bank_dest, account_id_dest)
+);
+
+CREATE TABLE transfer_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    account_number varchar(13) NOT NULL,
+    name varchar(100) NOT NULL,
+    from_account varchar(60) NOT NULL,
+    created_by varchar(60) NOT NULL,
+    created_at timestamp NOT NULL DEFAULT NOW(),
+    UNIQUE (account_number, name)
+);
+
+CREATE TABLE transfer_template_items (
+    id serial PRIMARY KEY,
+    template_id UUID NOT NULL REFERENCES transfer_templates(id),
+    beneficiary_id UUID NOT NULL REFERENCES beneficiaries(id),
+    position int NOT NULL,
+    amount DECIMAL(15,2) NOT NULL DEFAULT 0,
+    description text,
+    UNIQUE (template_id, beneficiary_id)
+);
+
+CREATE TABLE banks (
+    code varchar(20) PRIMARY KEY,
+    name varchar(100) NOT NULL,
+    swift_code varchar(11) NOT NULL DEFAULT '',
+    account_pattern varchar(255) NOT NULL DEFAULT '',
+    active boolean NOT NULL DEFAULT true,
+    created_at timestamp NOT NULL DEFAULT NOW(),
+    updated_at timestamp NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE transfer_limits (
+    id serial PRIMARY KEY,
+    account_number varchar(13) NOT NULL,
+    user_id varchar(60) NOT NULL DEFAULT '',
+    currency char(3) NOT NULL DEFAULT 'IDR',
+    max_per_row DECIMAL(15,2) NOT NULL DEFAULT 0 CHECK (max_per_row >= 0),
+    max_per_batch DECIMAL(15,2) NOT NULL DEFAULT 0 CHECK (max_per_batch >= 0),
+    daily_limit DECIMAL(15,2) NOT NULL DEFAULT 0 CHECK (daily_limit >= 0),
+    updated_at timestamp NOT NULL DEFAULT NOW(),
+    UNIQUE (account_number, user_id, currency)
+);
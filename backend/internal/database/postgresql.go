@@ -15,7 +15,7 @@ type DB struct {
 }
 
 func NewDB(driver, dbDsn string, log *log.Logger) (*DB, error) {
-	db, err := sql.Open("postgres", dbDsn)
+	db, err := sql.Open(driver, dbDsn)
 	if err != nil {
 		panic(err)
 	}
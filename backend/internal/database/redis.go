@@ -7,6 +7,9 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+// ErrKeyNotFound is returned by Get and GetDel for a missing key.
+var ErrKeyNotFound = redis.Nil
+
 type RedisClient struct {
 	redisClient *redis.Client
 }
@@ -24,3 +27,56 @@ func (r *RedisClient) Get(ctx context.Context, key string) (string, error) {
 func (r *RedisClient) Set(ctx context.Context, key string, value string, expiration time.Duration) error {
 	return r.redisClient.Set(ctx, key, value, expiration).Err()
 }
+
+func (r *RedisClient) SetNX(ctx context.Context, key string, value string, expiration time.Duration) (bool, error) {
+	return r.redisClient.SetNX(ctx, key, value, expiration).Result()
+}
+
+func (r *RedisClient) Del(ctx context.Context, keys ...string) error {
+	return r.redisClient.Del(ctx, keys...).Err()
+}
+
+// GetDel returns the value of key and deletes it in one step, so only one
+// caller can read a value.
+func (r *RedisClient) GetDel(ctx context.Context, key string) (string, error) {
+	return r.redisClient.GetDel(ctx, key).Result()
+}
+
+var incrWithExpiryScript = redis.NewScript(`
+local count = redis.call('INCR', KEYS[1])
+if count == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return count
+`)
+
+// IncrWithExpiry increments the counter at key and returns its new value. A
+// new counter expires after expiration, which makes it a fixed window counter.
+func (r *RedisClient) IncrWithExpiry(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	return incrWithExpiryScript.Run(ctx, r.redisClient, []string{key}, expiration.Milliseconds()).Int64()
+}
+
+var delIfEqualScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// DelIfEqual deletes key only when it holds value and reports whether it did,
+// so only one caller can use up a value.
+func (r *RedisClient) DelIfEqual(ctx context.Context, key string, value string) (bool, error) {
+	deleted, err := delIfEqualScript.Run(ctx, r.redisClient, []string{key}, value).Int64()
+	if err != nil {
+		return false, err
+	}
+	return deleted == 1, nil
+}
+
+func (r *RedisClient) Exists(ctx context.Context, key string) (bool, error) {
+	count, err := r.redisClient.Exists(ctx, key).Result()
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
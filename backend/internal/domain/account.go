@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// LinkedAccount is a source account registered to a corporate in addition to
+// the corporate's own account number.
+type LinkedAccount struct {
+	ID            int       `json:"id"`
+	AccountNumber string    `json:"-"`
+	LinkedAccount string    `json:"linked_account"`
+	CreatedBy     string    `json:"created_by"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// CorporateAccount records which corporate holds an account at the bank. Only
+// accounts held by a corporate can be linked to it.
+type CorporateAccount struct {
+	AccountNumber string
+	Owner         string
+}
+
+type LinkedAccountRequest struct {
+	LinkedAccount string `json:"linked_account" validate:"required,max=60"`
+
+	AccountNumber string `json:"-"`
+	UserID        string `json:"-"`
+	Role          Role   `json:"-"`
+}
+
+type LinkedAccountResponse struct {
+	Message string `json:"message"`
+}
+
+type LinkedAccountListResponse struct {
+	Data []LinkedAccount `json:"data"`
+}
+
+func ValidateLinkedAccountRequest(req *LinkedAccountRequest) error {
+	validate := validator.New()
+	err := validate.Struct(req)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+type LinkedAccountRepository interface {
+	// CreateLinkedAccount returns ErrLinkedAccountExists when the account is
+	// already linked to the corporate.
+	CreateLinkedAccount(ctx context.Context, linkedAccount LinkedAccount) error
+	GetLinkedAccounts(ctx context.Context, accountNumber string) ([]LinkedAccount, error)
+	IsLinkedAccount(ctx context.Context, accountNumber string, linkedAccount string) (bool, error)
+	// GetAccountOwner returns the corporate holding account, or
+	// ErrAccountNotOwned when the bank has no record of it.
+	GetAccountOwner(ctx context.Context, account string) (string, error)
+	// SaveCorporateAccount records or moves an account to its owner.
+	SaveCorporateAccount(ctx context.Context, account CorporateAccount) error
+}
@@ -0,0 +1,147 @@
+package domain
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Bank is an entry of the bank directory. AccountPattern is a regular
+// expression every account number at the bank must match, empty when any
+// account number is accepted.
+type Bank struct {
+	Code           string    `json:"code"`
+	Name           string    `json:"name"`
+	SwiftCode      string    `json:"swift_code"`
+	AccountPattern string    `json:"account_pattern"`
+	Active         bool      `json:"active"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type BankRequest struct {
+	Code           string `json:"code"`
+	Name           string `json:"name"`
+	SwiftCode      string `json:"swift_code"`
+	AccountPattern string `json:"account_pattern"`
+	Active         *bool  `json:"active"`
+
+	Role Role `json:"-"`
+}
+
+type BankResponse struct {
+	Message string `json:"message"`
+}
+
+type BankListResponse struct {
+	Data []Bank `json:"data"`
+}
+
+var (
+	bankCodePattern  = regexp.MustCompile(`^[A-Z0-9]{2,20}$`)
+	swiftCodePattern = regexp.MustCompile(`^[A-Z]{6}[A-Z0-9]{2}([A-Z0-9]{3})?$`)
+)
+
+// ValidateBank checks a directory entry before it is saved.
+func ValidateBank(bank Bank) []FieldError {
+	var fieldErrors []FieldError
+
+	if !bankCodePattern.MatchString(bank.Code) {
+		fieldErrors = append(fieldErrors, FieldError{Field: "code", Message: "code must be 2 to 20 letters or digits"})
+	}
+	if bank.Name == "" || len(bank.Name) > 100 {
+		fieldErrors = append(fieldErrors, FieldError{Field: "name", Message: "name is required and must be at most 100 characters"})
+	}
+	if bank.SwiftCode != "" && !swiftCodePattern.MatchString(bank.SwiftCode) {
+		fieldErrors = append(fieldErrors, FieldError{Field: "swift_code", Message: "swift_code must be an 8 or 11 character SWIFT/BIC code"})
+	}
+	if bank.AccountPattern != "" {
+		if _, err := regexp.Compile(bank.AccountPattern); err != nil {
+			fieldErrors = append(fieldErrors, FieldError{Field: "account_pattern", Message: "account_pattern is not a valid regular expression"})
+		}
+	}
+
+	return fieldErrors
+}
+
+// BankDirectory resolves the bank names found in transfer rows to directory
+// entries. A nil directory knows no bank.
+type BankDirectory struct {
+	banks    map[string]Bank
+	names    map[string]string
+	patterns map[string]*regexp.Regexp
+}
+
+// NewBankDirectory indexes banks by code, SWIFT/BIC code and name. Inactive
+// banks are left out.
+func NewBankDirectory(banks []Bank) *BankDirectory {
+	directory := &BankDirectory{
+		banks:    map[string]Bank{},
+		names:    map[string]string{},
+		patterns: map[string]*regexp.Regexp{},
+	}
+	for _, bank := range banks {
+		if !bank.Active {
+			continue
+		}
+		directory.banks[bank.Code] = bank
+		directory.names[normalizeBankName(bank.Name)] = bank.Code
+		if bank.SwiftCode != "" {
+			directory.names[bank.SwiftCode[:8]] = bank.Code
+		}
+		if pattern, err := regexp.Compile(bank.AccountPattern); err == nil && bank.AccountPattern != "" {
+			directory.patterns[bank.Code] = pattern
+		}
+	}
+	// codes win over names and SWIFT codes that happen to look the same
+	for code := range directory.banks {
+		directory.names[code] = code
+	}
+	return directory
+}
+
+func normalizeBankName(name string) string {
+	return strings.Join(strings.Fields(strings.ToUpper(name)), " ")
+}
+
+// Lookup finds a bank by its code, SWIFT/BIC code or name, ignoring case and
+// extra spaces. An 11 character SWIFT/BIC code of any branch finds the bank.
+func (d *BankDirectory) Lookup(name string) (Bank, bool) {
+	if d == nil {
+		return Bank{}, false
+	}
+	key := normalizeBankName(name)
+	code, ok := d.names[key]
+	if !ok && swiftCodePattern.MatchString(key) {
+		code, ok = d.names[key[:8]]
+	}
+	if !ok {
+		return Bank{}, false
+	}
+	return d.banks[code], true
+}
+
+// IsValidAccount reports whether accountID matches the account number format
+// of the bank with the given code.
+func (d *BankDirectory) IsValidAccount(code string, accountID string) bool {
+	if d == nil {
+		return false
+	}
+	pattern, ok := d.patterns[code]
+	if !ok {
+		return true
+	}
+	return pattern.MatchString(accountID)
+}
+
+type BankRepository interface {
+	GetBanks(ctx context.Context) ([]Bank, error)
+	// CreateBank returns ErrBankExists when the code is already used.
+	CreateBank(ctx context.Context, bank Bank) error
+	// UpdateBank returns ErrBankNotFound for an unknown code.
+	UpdateBank(ctx context.Context, bank Bank) error
+	// SeedBanks adds the banks whose code is not in the directory yet and
+	// leaves existing entries untouched.
+	SeedBanks(ctx context.Context, banks []Bank) error
+}
@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	null "gopkg.in/guregu/null.v4"
+)
+
+// Beneficiary is a saved transfer destination of a corporate.
+type Beneficiary struct {
+	ID              uuid.UUID   `json:"id"`
+	AccountNumber   string      `json:"-"`
+	BankDest        string      `json:"bank_dest"`
+	AccountIDDest   string      `json:"account_id_dest"`
+	AccountNameDest string      `json:"account_name_dest"`
+	Currency        string      `json:"currency"`
+	Description     null.String `json:"description"`
+	CreatedBy       string      `json:"created_by"`
+	CreatedAt       time.Time   `json:"created_at"`
+}
+
+// Detail returns a transfer row paying amount to the beneficiary.
+func (b Beneficiary) Detail(amount Money) TransactionDetail {
+	return TransactionDetail{
+		BankDest:        b.BankDest,
+		AccountIDDest:   b.AccountIDDest,
+		AccountNameDest: b.AccountNameDest,
+		Amount:          amount,
+		Currency:        b.Currency,
+		Description:     b.Description,
+	}
+}
+
+type BeneficiaryRequest struct {
+	BankDest        string `json:"bank_dest"`
+	AccountIDDest   string `json:"account_id_dest"`
+	AccountNameDest string `json:"account_name_dest"`
+	Currency        string `json:"currency"`
+	Description     string `json:"description"`
+
+	AccountNumber string `json:"-"`
+	UserID        string `json:"-"`
+	Role          Role   `json:"-"`
+}
+
+type BeneficiaryResponse struct {
+	ID      uuid.UUID `json:"id"`
+	Message string    `json:"message"`
+}
+
+type BeneficiaryListResponse struct {
+	Data []Beneficiary `json:"data"`
+}
+
+// TransferTemplate is a saved batch of beneficiaries that makers can send
+// again with new amounts.
+type TransferTemplate struct {
+	ID            uuid.UUID      `json:"id"`
+	AccountNumber string         `json:"-"`
+	Name          string         `json:"name"`
+	FromAccount   string         `json:"from_account"`
+	Items         []TemplateItem `json:"items"`
+	CreatedBy     string         `json:"created_by"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+// TemplateItem pays one beneficiary of a template. Amount is zero when the
+// amount must be given on every use, and Description overrides the
+// beneficiary's default description.
+type TemplateItem struct {
+	Beneficiary Beneficiary `json:"beneficiary"`
+	Amount      Money       `json:"amount"`
+	Description null.String `json:"description"`
+}
+
+type TemplateRequest struct {
+	Name        string                `json:"name"`
+	FromAccount string                `json:"from_account"`
+	Items       []TemplateItemRequest `json:"items"`
+
+	AccountNumber string `json:"-"`
+	UserID        string `json:"-"`
+	Role          Role   `json:"-"`
+}
+
+type TemplateItemRequest struct {
+	BeneficiaryID uuid.UUID `json:"beneficiary_id"`
+	Amount        Money     `json:"amount"`
+	Description   string    `json:"description"`
+}
+
+type TemplateResponse struct {
+	ID      uuid.UUID `json:"id"`
+	Message string    `json:"message"`
+}
+
+type TemplateListResponse struct {
+	Data []TransferTemplate `json:"data"`
+}
+
+type BeneficiaryRepository interface {
+	// CreateBeneficiary returns ErrBeneficiaryExists when the corporate already
+	// saved the same bank and account number.
+	CreateBeneficiary(ctx context.Context, beneficiary Beneficiary) error
+	GetBeneficiaries(ctx context.Context, accountNumber string) ([]Beneficiary, error)
+	GetBeneficiariesByID(ctx context.Context, accountNumber string, ids []uuid.UUID) ([]Beneficiary, error)
+	// CreateTemplate returns ErrTemplateExists when the corporate already has
+	// a template with the same name.
+	CreateTemplate(ctx context.Context, template TransferTemplate) error
+	GetTemplates(ctx context.Context, accountNumber string) ([]TransferTemplate, error)
+	// GetTemplateByID returns ErrTemplateNotFound for an unknown id or a
+	// template of another corporate.
+	GetTemplateByID(ctx context.Context, accountNumber string, id uuid.UUID) (TransferTemplate, error)
+}
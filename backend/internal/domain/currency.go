@@ -0,0 +1,137 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultCurrency is used for batches and rows that do not name a currency.
+const DefaultCurrency = "IDR"
+
+// currencyDecimals lists the supported ISO 4217 currencies with the number of
+// decimal places each allows. Money keeps two decimal places, so currencies
+// with three minor digits are not supported.
+var currencyDecimals = map[string]int{
+	"AUD": 2,
+	"CNY": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"HKD": 2,
+	"IDR": 2,
+	"JPY": 0,
+	"KRW": 0,
+	"MYR": 2,
+	"PHP": 2,
+	"SGD": 2,
+	"THB": 2,
+	"USD": 2,
+	"VND": 0,
+}
+
+// CurrencyDecimals returns the decimal places allowed for an ISO 4217 code and
+// whether the currency is supported.
+func CurrencyDecimals(currency string) (int, bool) {
+	decimals, ok := currencyDecimals[currency]
+	return decimals, ok
+}
+
+// ValidateCurrencyAmount checks that amount has no more decimal places than
+// its currency allows.
+func ValidateCurrencyAmount(currency string, amount Money) error {
+	decimals, ok := CurrencyDecimals(currency)
+	if !ok {
+		return fmt.Errorf("unsupported currency %q", currency)
+	}
+
+	unit := Money(1)
+	for i := decimals; i < moneyMaxDecimals; i++ {
+		unit *= 10
+	}
+	if amount%unit != 0 {
+		return fmt.Errorf("%s amounts allow at most %d decimal places", currency, decimals)
+	}
+
+	return nil
+}
+
+// NormalizeCurrency upper-cases a currency code and falls back to fallback
+// when it is empty.
+func NormalizeCurrency(currency string, fallback string) string {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return fallback
+	}
+	return currency
+}
+
+// CurrencyTotal is the sum and row count of one currency within a batch.
+type CurrencyTotal struct {
+	Currency    string `json:"currency"`
+	TotalAmount Money  `json:"total_amount"`
+	TotalRecord int    `json:"total_record"`
+}
+
+// CurrencySummary is the amount per status of one currency across batches.
+type CurrencySummary struct {
+	Currency             string `json:"currency"`
+	TotalWaitingApproval Money  `json:"total_waiting_approval"`
+	TotalApproved        Money  `json:"total_approved"`
+	TotalRejected        Money  `json:"total_rejected"`
+}
+
+// ParseCurrencyTotals parses declared per-currency totals formatted as
+// "USD:100.00,SGD:25.50".
+func ParseCurrencyTotals(value string) (map[string]Money, error) {
+	totals := map[string]Money{}
+	if strings.TrimSpace(value) == "" {
+		return totals, nil
+	}
+
+	for _, entry := range strings.Split(value, ",") {
+		currency, rawAmount, found := strings.Cut(entry, ":")
+		if !found {
+			return nil, fmt.Errorf("currency total %q must be formatted as CURRENCY:AMOUNT", entry)
+		}
+
+		currency = NormalizeCurrency(currency, "")
+		if _, ok := CurrencyDecimals(currency); !ok {
+			return nil, fmt.Errorf("unsupported currency %q", currency)
+		}
+		if _, duplicate := totals[currency]; duplicate {
+			return nil, fmt.Errorf("currency %s is declared more than once", currency)
+		}
+
+		amount, err := ParseMoney(rawAmount, moneyMaxDecimals)
+		if err != nil {
+			return nil, fmt.Errorf("%s total: %s", currency, err.Error())
+		}
+		totals[currency] = amount
+	}
+
+	return totals, nil
+}
+
+// SumByCurrency returns the total of every currency in details, sorted by
+// currency code.
+func SumByCurrency(details []TransactionDetail) []CurrencyTotal {
+	totals := map[string]*CurrencyTotal{}
+	for _, detail := range details {
+		total, ok := totals[detail.Currency]
+		if !ok {
+			total = &CurrencyTotal{Currency: detail.Currency}
+			totals[detail.Currency] = total
+		}
+		total.TotalAmount += detail.Amount
+		total.TotalRecord++
+	}
+
+	var result []CurrencyTotal
+	for _, total := range totals {
+		result = append(result, *total)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Currency < result[j].Currency
+	})
+	return result
+}
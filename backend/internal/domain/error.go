@@ -1,6 +1,10 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
 
 var (
 	ErrorUserAlreadyExist       = errors.New("user already exist")
@@ -8,11 +12,55 @@ var (
 	ErrInvalidPassword          = errors.New("invalid password")
 	ErrInvalidToken             = errors.New("invalid token")
 	ErrInvalidOTP               = errors.New("invalid otp")
+	ErrOTPLocked                = errors.New("too many wrong otp attempts, try again later")
+	ErrOTPRateLimited           = errors.New("too many otp requests, try again later")
+	ErrOTPEmailRequired         = errors.New("email is required to send an otp by email")
+	ErrOTPPhoneNumberRequired   = errors.New("phone_number is required to send an otp by sms")
+	ErrOTPRequired              = errors.New("otp_code is required to approve this transaction")
 	ErrInvalidTransactionStatus = errors.New("invalid transaction status")
 	ErrInvalidTransactionID     = errors.New("invalid transaction id")
+	ErrIdempotencyKeyConflict   = errors.New("idempotency key was already used with a different request")
+	ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is still in progress")
+	ErrInvalidIdempotencyKey    = errors.New("idempotency key must be at most 255 characters")
+	ErrTransactionNotFound      = errors.New("transaction not found")
+	ErrInvalidTransition        = errors.New("transaction status cannot be changed from its current status")
+	ErrAlreadyApproved          = errors.New("you have already approved this transaction")
+	ErrRejectionReasonRequired  = errors.New("reason is required to reject a transaction")
+	ErrClearanceReasonRequired  = errors.New("reason is required to clear a transaction from compliance review")
+	ErrLinkedAccountExists      = errors.New("account is already linked to this corporate")
+	ErrTransferDateExpired      = errors.New("transfer date of this transaction has passed")
+	ErrRetryExists              = errors.New("failed transfers of this transaction are already being retried")
+	ErrBeneficiaryExists        = errors.New("beneficiary is already saved")
+	ErrTemplateExists           = errors.New("a template with this name already exists")
+	ErrTemplateNotFound         = errors.New("template not found")
+	ErrBankExists               = errors.New("a bank with this code already exists")
+	ErrBankNotFound             = errors.New("bank not found")
+	ErrInquiryMismatch          = errors.New("account names of some transfers do not match the destination bank")
+	ErrInquiryPending           = errors.New("account names of this transaction are still being checked")
+	ErrInvalidMoney             = errors.New("amount is not a valid number")
+	ErrMoneyPrecision           = errors.New("amount has too many decimal places")
+	ErrMoneyOutOfRange          = errors.New("amount is too large")
+)
+
+// ForbiddenError is returned when the caller's role or relation to a
+// transaction does not allow the requested action.
+type ForbiddenError struct {
+	Message string
+}
+
+func (e *ForbiddenError) Error() string {
+	return e.Message
+}
+
+var (
+	ErrRoleNotAllowed  = &ForbiddenError{Message: "your role is not allowed to perform this action"}
+	ErrSelfApproval    = &ForbiddenError{Message: "maker cannot approve or reject their own transaction"}
+	ErrNotMaker        = &ForbiddenError{Message: "only the maker of a transaction can cancel it"}
+	ErrAccountNotOwned = &ForbiddenError{Message: "account is not held by your corporate"}
 )
 
 type FieldError struct {
+	Line    int    `json:"line,omitempty"`
 	Field   string `json:"field"`
 	Message string `json:"message"`
 }
@@ -22,6 +70,37 @@ type FieldErrorResponse struct {
 	Errors  []FieldError `json:"errors"`
 }
 
+// ValidationError carries a list of field errors so handlers can render them as
+// a FieldErrorResponse.
+type ValidationError struct {
+	Message string
+	Errors  []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// ErrMixedCurrency rejects an upload asking for mixed currencies while the
+// feature is disabled.
+var ErrMixedCurrency = &ValidationError{
+	Message: "Validation failed",
+	Errors: []FieldError{{
+		Field:   "mixed_currency",
+		Message: "mixed currency batches are not enabled",
+	}},
+}
+
+// DuplicateTransactionError is returned when an upload is identical to a
+// recent batch and the maker has not confirmed the duplicate.
+type DuplicateTransactionError struct {
+	TransactionID uuid.UUID
+}
+
+func (e *DuplicateTransactionError) Error() string {
+	return "identical batch was already uploaded from this account as transaction " + e.TransactionID.String()
+}
+
 type CommonErrorResponse struct {
 	Message string `json:"message"`
 }
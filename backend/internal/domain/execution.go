@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"context"
+)
+
+// DetailStatus is the execution status of a single transfer in a batch.
+type DetailStatus string
+
+const (
+	DetailPending DetailStatus = "pending"
+	DetailSuccess DetailStatus = "success"
+	DetailFailed  DetailStatus = "failed"
+)
+
+// TransferExecutor sends a single transfer to the bank. The detail ID is
+// passed on as the transfer reference, so an executor must treat a repeated
+// reference as the same transfer. That makes it safe to resume a batch after
+// a restart.
+//
+// A returned error marks the transfer as failed with the error as reason,
+// unless ctx was cancelled, in which case the transfer stays pending.
+type TransferExecutor interface {
+	Transfer(ctx context.Context, fromAccount string, detail TransactionDetail) error
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"context"
+)
+
+// InquiryResult tells whether the destination bank confirmed that a transfer
+// row's account number belongs to its account name.
+type InquiryResult string
+
+const (
+	// InquiryPending rows have not been checked yet.
+	InquiryPending  InquiryResult = "pending"
+	InquiryMatch    InquiryResult = "match"
+	InquiryMismatch InquiryResult = "mismatch"
+	// InquiryUnknown rows could not be checked, for example because the bank
+	// does not know the account or did not answer.
+	InquiryUnknown InquiryResult = "unknown"
+)
+
+// BeneficiaryInquiry asks the destination bank whether AccountIDDest of a
+// transfer row is held by AccountNameDest. A returned error is recorded as
+// InquiryUnknown, unless ctx was cancelled, in which case the row is checked
+// again later.
+type BeneficiaryInquiry interface {
+	Inquire(ctx context.Context, detail TransactionDetail) (InquiryResult, error)
+}
+
+// InquirySummary counts the rows of a batch by inquiry result.
+type InquirySummary struct {
+	Pending  int `json:"pending"`
+	Match    int `json:"match"`
+	Mismatch int `json:"mismatch"`
+	Unknown  int `json:"unknown"`
+}
+
+func SummarizeInquiries(details []TransactionDetail) InquirySummary {
+	var summary InquirySummary
+	for _, detail := range details {
+		switch detail.InquiryResult {
+		case InquiryPending:
+			summary.Pending++
+		case InquiryMatch:
+			summary.Match++
+		case InquiryMismatch:
+			summary.Mismatch++
+		case InquiryUnknown:
+			summary.Unknown++
+		}
+	}
+	return summary
+}
@@ -0,0 +1,172 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// LimitWindow is the rolling period the daily limit applies to.
+const LimitWindow = 24 * time.Hour
+
+const (
+	LimitMaxPerRow   = "max_per_row"
+	LimitMaxPerBatch = "max_per_batch"
+	LimitDaily       = "daily_limit"
+
+	LimitScopeCorporate = "corporate"
+	LimitScopeUser      = "user"
+)
+
+// TransferLimit caps the amounts in Currency sent by a corporate, or by one of
+// its makers when UserID is set. A zero amount means no limit.
+type TransferLimit struct {
+	AccountNumber string `json:"account_number"`
+	UserID        string `json:"user_id"`
+	Currency      string `json:"currency"`
+	MaxPerRow     Money  `json:"max_per_row"`
+	MaxPerBatch   Money  `json:"max_per_batch"`
+	DailyLimit    Money  `json:"daily_limit"`
+}
+
+func (l TransferLimit) Scope() string {
+	if l.UserID != "" {
+		return LimitScopeUser
+	}
+	return LimitScopeCorporate
+}
+
+type TransferLimitRequest struct {
+	UserID      string `json:"user_id"`
+	Currency    string `json:"currency"`
+	MaxPerRow   Money  `json:"max_per_row"`
+	MaxPerBatch Money  `json:"max_per_batch"`
+	DailyLimit  Money  `json:"daily_limit"`
+
+	AccountNumber string `json:"-"`
+	Role          Role   `json:"-"`
+}
+
+type TransferLimitResponse struct {
+	Message string `json:"message"`
+}
+
+type TransferLimitListResponse struct {
+	Data []TransferLimit `json:"data"`
+}
+
+// LimitUsage is the amount per currency of the batches approved within the
+// LimitWindow, for the corporate and for the maker of the checked batch.
+type LimitUsage struct {
+	Corporate map[string]Money
+	User      map[string]Money
+}
+
+// LimitExceededError names the limit a batch breaches and the headroom left
+// under it. A row breaching LimitMaxPerRow has no headroom but the file line
+// of the row, when the rows come from an uploaded file.
+type LimitExceededError struct {
+	Limit     string
+	Scope     string
+	Currency  string
+	Amount    Money
+	Remaining *Money
+	Line      int
+}
+
+func (e *LimitExceededError) Error() string {
+	if e.Limit == LimitMaxPerRow {
+		if e.Line > 0 {
+			return fmt.Sprintf("line %d exceeds the %s %s of %s %s", e.Line, e.Scope, e.Limit, e.Currency, e.Amount)
+		}
+		return fmt.Sprintf("a row exceeds the %s %s of %s %s", e.Scope, e.Limit, e.Currency, e.Amount)
+	}
+	return fmt.Sprintf("transaction exceeds the %s %s of %s %s, %s %s remaining", e.Scope, e.Limit, e.Currency, e.Amount, e.Currency, *e.Remaining)
+}
+
+type LimitExceededResponse struct {
+	Message   string `json:"message"`
+	Limit     string `json:"limit"`
+	Scope     string `json:"scope"`
+	Currency  string `json:"currency"`
+	Amount    Money  `json:"amount"`
+	Remaining *Money `json:"remaining,omitempty"`
+	Line      int    `json:"line,omitempty"`
+}
+
+// CheckTransferLimits returns a *LimitExceededError for the first limit the
+// rows of a batch breach, checking row, batch and daily limits in that order.
+func CheckTransferLimits(limits []TransferLimit, details []TransactionDetail, usage LimitUsage) error {
+	totals := map[string]Money{}
+	for _, detail := range details {
+		totals[detail.Currency] += detail.Amount
+	}
+
+	for _, limit := range limits {
+		if limit.MaxPerRow > 0 {
+			for _, detail := range details {
+				if detail.Currency == limit.Currency && detail.Amount > limit.MaxPerRow {
+					return &LimitExceededError{
+						Limit:    LimitMaxPerRow,
+						Scope:    limit.Scope(),
+						Currency: limit.Currency,
+						Amount:   limit.MaxPerRow,
+						Line:     detail.Line,
+					}
+				}
+			}
+		}
+	}
+
+	for _, limit := range limits {
+		if limit.MaxPerBatch > 0 && totals[limit.Currency] > limit.MaxPerBatch {
+			remaining := limit.MaxPerBatch
+			return &LimitExceededError{
+				Limit:     LimitMaxPerBatch,
+				Scope:     limit.Scope(),
+				Currency:  limit.Currency,
+				Amount:    limit.MaxPerBatch,
+				Remaining: &remaining,
+			}
+		}
+	}
+
+	for _, limit := range limits {
+		if limit.DailyLimit <= 0 {
+			continue
+		}
+		used := usage.Corporate[limit.Currency]
+		if limit.Scope() == LimitScopeUser {
+			used = usage.User[limit.Currency]
+		}
+		if used+totals[limit.Currency] > limit.DailyLimit {
+			remaining := limit.DailyLimit - used
+			if remaining < 0 {
+				remaining = 0
+			}
+			return &LimitExceededError{
+				Limit:     LimitDaily,
+				Scope:     limit.Scope(),
+				Currency:  limit.Currency,
+				Amount:    limit.DailyLimit,
+				Remaining: &remaining,
+			}
+		}
+	}
+
+	return nil
+}
+
+type LimitRepository interface {
+	// GetTransferLimits returns the limits of the corporate, together with
+	// those of userID when it is not empty.
+	GetTransferLimits(ctx context.Context, accountNumber string, userID string) ([]TransferLimit, error)
+	// ListTransferLimits returns every limit of the corporate and its users.
+	ListTransferLimits(ctx context.Context, accountNumber string) ([]TransferLimit, error)
+	// GetLimitUsage sums the batches of the corporate, and of maker, approved
+	// since the given time.
+	GetLimitUsage(ctx context.Context, accountNumber string, maker string, since time.Time) (LimitUsage, error)
+	// SaveTransferLimit creates or replaces the limit of the corporate, or of
+	// a user, in a currency.
+	SaveTransferLimit(ctx context.Context, limit TransferLimit) error
+}
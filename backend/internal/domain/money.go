@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Money is an exact amount in hundredths of a currency unit, matching the
+// DECIMAL(15,2) columns it is stored in. It is encoded in JSON as a decimal
+// number with two fraction digits.
+type Money int64
+
+const (
+	moneyScale       = 100
+	moneyMaxDecimals = 2
+	// moneyMaxIntegerDigits is the number of digits DECIMAL(15,2) allows
+	// before the decimal point.
+	moneyMaxIntegerDigits = 13
+)
+
+// MoneyMaxDecimals is the largest number of decimal places Money can hold.
+const MoneyMaxDecimals = moneyMaxDecimals
+
+// ParseMoney parses a plain decimal string such as "1500" or "1500.50".
+// Thousand separators and exponents are rejected, as are amounts with more
+// than decimals fraction digits.
+func ParseMoney(value string, decimals int) (Money, error) {
+	value = strings.TrimSpace(value)
+
+	negative := false
+	if strings.HasPrefix(value, "-") || strings.HasPrefix(value, "+") {
+		negative = value[0] == '-'
+		value = value[1:]
+	}
+
+	integerPart, fractionPart, _ := strings.Cut(value, ".")
+	if integerPart == "" && fractionPart == "" || !isDigits(integerPart) || !isDigits(fractionPart) {
+		return 0, ErrInvalidMoney
+	}
+	if len(fractionPart) > decimals || len(fractionPart) > moneyMaxDecimals {
+		return 0, ErrMoneyPrecision
+	}
+
+	integerPart = strings.TrimLeft(integerPart, "0")
+	if len(integerPart) > moneyMaxIntegerDigits {
+		return 0, ErrMoneyOutOfRange
+	}
+
+	units, _ := strconv.ParseInt("0"+integerPart, 10, 64)
+	fraction, _ := strconv.ParseInt((fractionPart + "00")[:2], 10, 64)
+
+	amount := Money(units*moneyScale + fraction)
+	if negative {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+func isDigits(value string) bool {
+	for _, c := range value {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (m Money) String() string {
+	sign := ""
+	value := int64(m)
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, value/moneyScale, value%moneyScale)
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts both a JSON number and a quoted decimal string.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+	parsed, err := ParseMoney(value, moneyMaxDecimals)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Scan reads a DECIMAL column, which the postgres driver returns as text.
+func (m *Money) Scan(src any) error {
+	switch value := src.(type) {
+	case []byte:
+		return m.scanString(string(value))
+	case string:
+		return m.scanString(value)
+	case int64:
+		*m = Money(value * moneyScale)
+		return nil
+	case nil:
+		*m = 0
+		return nil
+	}
+	return fmt.Errorf("cannot scan %T into Money", src)
+}
+
+func (m *Money) scanString(value string) error {
+	parsed, err := ParseMoney(value, moneyMaxDecimals)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
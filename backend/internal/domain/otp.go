@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"context"
+)
+
+// OTPRecipient is who a one-time code is delivered to. Each OTPSender uses
+// the contact of its own channel.
+type OTPRecipient struct {
+	Email       string
+	PhoneNumber string
+}
+
+// OTPSender delivers a one-time code over a channel such as email or SMS.
+// Purpose tells the recipient what the code confirms, for example
+// "registration". A sender returns ErrOTPPhoneNumberRequired or
+// ErrOTPEmailRequired when the recipient has no contact for its channel.
+type OTPSender interface {
+	SendOTP(ctx context.Context, recipient OTPRecipient, purpose string, code string) error
+}
+
+// OTPDeliveryError is returned when a one-time code could not be delivered,
+// even after retrying. The code is not kept, so it has to be requested again.
+type OTPDeliveryError struct {
+	Err error
+}
+
+func (e *OTPDeliveryError) Error() string {
+	return "failed to deliver otp: " + e.Err.Error()
+}
+
+func (e *OTPDeliveryError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// TransferDateLayout is the format of transfer dates sent by makers.
+const TransferDateLayout = "2006-01-02"
+
+// TransferCalendar decides on which dates transfers can be executed. Transfer
+// dates are calendar dates, stored as midnight UTC, while the cut-off time and
+// "today" are evaluated in Location.
+type TransferCalendar struct {
+	// CutOff is the time of day after which transfers can no longer be
+	// scheduled for the same day.
+	CutOff   time.Duration
+	Holidays map[string]bool
+	Location *time.Location
+}
+
+func NewTransferCalendar(cutOff time.Duration, holidays []string, location *time.Location) TransferCalendar {
+	calendar := TransferCalendar{
+		CutOff:   cutOff,
+		Holidays: map[string]bool{},
+		Location: location,
+	}
+	for _, holiday := range holidays {
+		calendar.Holidays[holiday] = true
+	}
+	return calendar
+}
+
+// TransferDay drops the time of day from t, keeping its calendar date.
+func TransferDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Today returns the current calendar date in the calendar's location.
+func (c TransferCalendar) Today(now time.Time) time.Time {
+	return TransferDay(now.In(c.Location))
+}
+
+// IsBusinessDay reports whether date is a weekday that is not a holiday.
+func (c TransferCalendar) IsBusinessDay(date time.Time) bool {
+	if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+		return false
+	}
+	return !c.Holidays[date.Format(TransferDateLayout)]
+}
+
+// EarliestTransferDate returns the first business day that can still be
+// executed at now: today when it is a business day before the cut-off,
+// otherwise the next business day.
+func (c TransferCalendar) EarliestTransferDate(now time.Time) time.Time {
+	local := now.In(c.Location)
+	date := TransferDay(local)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, c.Location)
+	if local.Sub(midnight) >= c.CutOff {
+		date = date.AddDate(0, 0, 1)
+	}
+	for !c.IsBusinessDay(date) {
+		date = date.AddDate(0, 0, 1)
+	}
+	return date
+}
+
+// ValidateTransferDate checks that date is a business day that has not passed
+// its cut-off at now.
+func (c TransferCalendar) ValidateTransferDate(date time.Time, now time.Time) error {
+	date = TransferDay(date)
+	if !c.IsBusinessDay(date) {
+		return errors.New("transfer_date " + date.Format(TransferDateLayout) + " is not a business day")
+	}
+	if date.Before(c.EarliestTransferDate(now)) {
+		return errors.New("transfer_date " + date.Format(TransferDateLayout) + " has passed the cut-off time")
+	}
+	return nil
+}
+
+// IsOverdue reports whether a transfer dated date can no longer be executed
+// at now.
+func (c TransferCalendar) IsOverdue(date time.Time, now time.Time) bool {
+	return TransferDay(date).Before(c.EarliestTransferDate(now))
+}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+)
+
+// WatchlistEntry is a sanctioned or watched party. Either field may be empty.
+type WatchlistEntry struct {
+	Name          string `json:"name"`
+	AccountNumber string `json:"account_number"`
+}
+
+const (
+	MatchedOnAccountNumber = "account_number"
+	MatchedOnName          = "name"
+)
+
+// ScreeningHit is a transfer row that matched a watchlist entry. Score is 1
+// for an account number match and the name similarity otherwise.
+type ScreeningHit struct {
+	TransactionDetailID uuid.UUID `json:"transaction_detail_id"`
+	WatchlistName       string    `json:"watchlist_name"`
+	WatchlistAccount    string    `json:"watchlist_account"`
+	MatchedOn           string    `json:"matched_on"`
+	Score               float64   `json:"score"`
+}
+
+// Screener checks the beneficiary of a transfer row against a watchlist.
+type Screener interface {
+	Screen(detail TransactionDetail) []ScreeningHit
+}
@@ -2,7 +2,9 @@ package domain
 
 import (
 	"context"
+	"fmt"
 	"mime/multipart"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,38 +17,119 @@ const (
 	WaitingApproval TransactionStatus = "waiting_approval"
 	Approved        TransactionStatus = "approved"
 	Rejected        TransactionStatus = "rejected"
+	Cancelled       TransactionStatus = "cancelled"
+	Processing      TransactionStatus = "processing"
+	Completed       TransactionStatus = "completed"
+	Failed          TransactionStatus = "failed"
+	Expired         TransactionStatus = "expired"
+	// PartiallyCompleted is a processed batch where some, but not all,
+	// transfers failed.
+	PartiallyCompleted TransactionStatus = "partially_completed"
+	// ComplianceReview is a new batch with a beneficiary on the watchlist. It
+	// waits for approval only once a compliance officer clears it.
+	ComplianceReview TransactionStatus = "compliance_review"
 )
 
+// transactionTransitions lists the statuses each status may move to. Statuses
+// missing from the map are final.
+var transactionTransitions = map[TransactionStatus][]TransactionStatus{
+	WaitingApproval:  {Approved, Rejected, Cancelled, Expired},
+	ComplianceReview: {WaitingApproval, Rejected, Cancelled, Expired},
+	Approved:         {Processing},
+	Processing:       {Completed, PartiallyCompleted, Failed},
+}
+
+// CanTransitionTo reports whether a transaction in status s may move to next.
+func (s TransactionStatus) CanTransitionTo(next TransactionStatus) bool {
+	for _, allowed := range transactionTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
 type Transaction struct {
 	ID                uuid.UUID `json:"id"`
-	TotalAmount       float64   `json:"total_amount"`
+	AccountNumber     string    `json:"account_number"`
+	TotalAmount       Money     `json:"total_amount"`
 	TotalRecord       int       `json:"total_record"`
+	Currency          string    `json:"currency"`
+	MixedCurrency     bool      `json:"mixed_currency"`
 	FromAccount       string    `json:"from_account"`
 	Maker             string    `json:"maker"`
 	TransferDate      time.Time `json:"transfer_date"`
 	TransactionStatus string    `json:"transaction_status"`
+	RequiredApprovals int       `json:"required_approvals"`
 	CreatedAt         time.Time `json:"created_at"`
+	// ParentTransactionID is set on a batch that retries the failed rows of
+	// another batch.
+	ParentTransactionID *uuid.UUID `json:"parent_transaction_id"`
+	// CurrencyTotals breaks the batch down by the currency of its rows.
+	// TotalAmount only covers rows in Currency.
+	CurrencyTotals []CurrencyTotal `json:"currency_totals"`
+	// Fingerprint identifies the exact set of rows in the batch, while
+	// BeneficiaryFingerprint ignores amounts and only covers who gets paid.
+	Fingerprint            string `json:"-"`
+	BeneficiaryFingerprint string `json:"-"`
 }
 
 type TransactionDetail struct {
-	ID              uuid.UUID   `json:"id"`
-	TransactionID   uuid.UUID   `json:"transaction_id"`
-	BankDest        string      `json:"bank_dest"`
-	AccountIDDest   string      `json:"account_id_dest"`
-	AccountNameDest string      `json:"account_name_dest"`
-	Amount          float64     `json:"amount"`
-	Description     null.String `json:"description"`
-	TransferDate    time.Time   `json:"transfer_date"`
+	ID              uuid.UUID    `json:"id"`
+	TransactionID   uuid.UUID    `json:"transaction_id"`
+	BankDest        string       `json:"bank_dest"`
+	AccountIDDest   string       `json:"account_id_dest"`
+	AccountNameDest string       `json:"account_name_dest"`
+	Amount          Money        `json:"amount"`
+	Currency        string       `json:"currency"`
+	Description     null.String  `json:"description"`
+	TransferDate    time.Time    `json:"transfer_date"`
+	Status          DetailStatus `json:"status"`
+	FailureReason   null.String  `json:"failure_reason"`
+	ExecutedAt      null.Time    `json:"executed_at"`
+	// InquiryResult is the outcome of the account name inquiry, made while
+	// the batch waits for approval.
+	InquiryResult InquiryResult `json:"inquiry_result"`
+	InquiredAt    null.Time     `json:"inquired_at"`
+	// Line is the line of the row in the uploaded file, the header being
+	// line 1. It is not stored.
+	Line int `json:"-"`
 }
 
 type TransactionSummaryResult struct {
-	TotalWaitingApproval int `json:"total_waiting_approval"`
-	TotalApproved        int `json:"total_approved"`
-	TotalRejected        int `json:"total_rejected"`
+	TotalWaitingApproval int               `json:"total_waiting_approval"`
+	TotalApproved        int               `json:"total_approved"`
+	TotalRejected        int               `json:"total_rejected"`
+	CurrencyTotals       []CurrencySummary `json:"currency_totals"`
 }
 
 type UpdateTransactionRequest struct {
 	Status string `json:"status"`
+	Reason string `json:"reason"`
+	// OTPCode confirms an approval, see ApprovalOTPRequest.
+	OTPCode string `json:"otp_code"`
+
+	TransactionID uuid.UUID `json:"-"`
+	UserID        string    `json:"-"`
+	Role          Role      `json:"-"`
+	AccountNumber string    `json:"-"`
+}
+
+// TransactionEvent is one entry of a transaction's append-only history. A nil
+// PreviousStatus marks the creation of the transaction.
+type TransactionEvent struct {
+	ID             int                `json:"id"`
+	TransactionID  uuid.UUID          `json:"transaction_id"`
+	Actor          string             `json:"actor"`
+	ActorRole      Role               `json:"actor_role"`
+	PreviousStatus *TransactionStatus `json:"previous_status"`
+	NewStatus      TransactionStatus  `json:"new_status"`
+	Reason         null.String        `json:"reason"`
+	CreatedAt      time.Time          `json:"created_at"`
+}
+
+type TransactionHistoryResponse struct {
+	Data []TransactionEvent `json:"data"`
 }
 
 type UpdateTransactionResponse struct {
@@ -68,33 +151,241 @@ type TransactionListResponse struct {
 }
 
 type TransactionListParam struct {
-	Page         int
-	PerPage      int
-	StatusFilter []TransactionStatus
+	AccountNumber string
+	Role          Role
+	Page          int
+	PerPage       int
+	StatusFilter  []TransactionStatus
+	// AllCorporates lists the batches of every corporate instead of
+	// AccountNumber's, for bank staff only.
+	AllCorporates bool
 }
 
 type TransactionDetailResponse struct {
-	Data []TransactionDetail `json:"data"`
+	Data     []TransactionDetail `json:"data"`
+	Approval ApprovalStatus      `json:"approval"`
+	Inquiry  InquirySummary      `json:"inquiry"`
+	// ScreeningHits lists the rows that matched the watchlist.
+	ScreeningHits []ScreeningHit `json:"screening_hits"`
+	// ParentTransactionID links a retry batch to the batch it retries, and
+	// RetryTransactionIDs lists the retries of this batch.
+	ParentTransactionID *uuid.UUID  `json:"parent_transaction_id"`
+	RetryTransactionIDs []uuid.UUID `json:"retry_transaction_ids"`
+}
+
+// ApprovalOTPRequest asks for the one-time code an approver needs to approve
+// a transaction. The code only approves that transaction for its amount.
+type ApprovalOTPRequest struct {
+	TransactionID uuid.UUID
+	UserID        string
+	Role          Role
+	AccountNumber string
+}
+
+// ApprovalOTPResponse tells whether approving needs a code. Without
+// OTPRequired no code was sent and the approval needs none.
+type ApprovalOTPResponse struct {
+	Message     string `json:"message"`
+	OTPRequired bool   `json:"otp_required"`
+	OTP         string `json:"otp,omitempty"`
+	ExpiresIn   int    `json:"expires_in,omitempty"`
+}
+
+type RetryTransactionRequest struct {
+	TransactionID uuid.UUID
+	UserID        string
+	Role          Role
+	AccountNumber string
+}
+
+// ApprovalPolicy requires RequiredApprovals distinct approvers for batches of
+// a corporate account whose total is at least MinAmount.
+type ApprovalPolicy struct {
+	AccountNumber     string `json:"account_number"`
+	MinAmount         Money  `json:"min_amount"`
+	RequiredApprovals int    `json:"required_approvals"`
+}
+
+type TransactionApproval struct {
+	TransactionID uuid.UUID `json:"-"`
+	Approver      string    `json:"approver"`
+	ApprovedAt    time.Time `json:"approved_at"`
+}
+
+// ApprovalProgress is the result of recording one approval on a batch.
+type ApprovalProgress struct {
+	Approvals         int
+	RequiredApprovals int
+	Approved          bool
+}
+
+// ApprovalStatus shows who has approved a batch so far and which approvers of
+// the corporate account have not yet done so.
+type ApprovalStatus struct {
+	RequiredApprovals int                   `json:"required_approvals"`
+	Approvals         []TransactionApproval `json:"approvals"`
+	PendingApprovers  []string              `json:"pending_approvers"`
 }
 
 type TransactionUploadRequest struct {
 	File        *multipart.File
-	TotalAmount float64
+	FileName    string
+	ContentType string
+	// TemplateID builds the batch from a saved template instead of File.
+	// Amounts overrides the template amount of its beneficiaries, and the
+	// declared totals may be left empty.
+	TemplateID  *uuid.UUID
+	Amounts     map[uuid.UUID]Money
+	TotalAmount Money
 	TotalRecord int
-	FromAccount string
-	UserID      string
+	Currency    string
+	// MixedCurrency must be set to upload rows in more than one currency.
+	// Totals of currencies other than Currency are declared in
+	// CurrencyTotals.
+	MixedCurrency  bool
+	CurrencyTotals map[string]Money
+	// TransferDate is the requested execution date, zero for the earliest
+	// possible date.
+	TransferDate   time.Time
+	FromAccount    string
+	UserID         string
+	Role           Role
+	AccountNumber  string
+	IdempotencyKey string
+	// ConfirmDuplicate lets the maker upload a batch identical to a recent one.
+	ConfirmDuplicate bool
 }
 
 type TransactionUploadResponse struct {
-	TotalRecord int     `json:"total_record"`
-	TotalAmount float64 `json:"total_amount"`
-	Message     string  `json:"message"`
+	TransactionID uuid.UUID `json:"transaction_id"`
+	TotalRecord   int       `json:"total_record"`
+	TotalAmount   Money     `json:"total_amount"`
+	Currency      string    `json:"currency"`
+	TransferDate  time.Time `json:"transfer_date"`
+	Message       string    `json:"message"`
+	// CurrencyTotals breaks the batch down by currency.
+	CurrencyTotals []CurrencyTotal `json:"currency_totals"`
+	// DuplicateOf points to a recent batch from the same source account that
+	// looks like this one, with Warning explaining the match.
+	DuplicateOf *uuid.UUID `json:"duplicate_of,omitempty"`
+	Warning     string     `json:"warning,omitempty"`
+	// Replayed is set when the response is returned from an earlier request
+	// with the same idempotency key.
+	Replayed bool `json:"-"`
+}
+
+// ValidateBeneficiary checks who a transfer row pays. Saved beneficiaries are
+// checked with it as well, so they follow the same rules as uploaded rows.
+// BankDest must already be normalized to a code of banks.
+func ValidateBeneficiary(detail TransactionDetail, banks *BankDirectory) []FieldError {
+	var fieldErrors []FieldError
+
+	if strings.TrimSpace(detail.BankDest) == "" {
+		fieldErrors = append(fieldErrors, FieldError{Field: "bank_dest", Message: "bank is required"})
+	} else if bank, ok := banks.Lookup(detail.BankDest); !ok || bank.Code != detail.BankDest {
+		fieldErrors = append(fieldErrors, FieldError{Field: "bank_dest", Message: "unknown bank " + detail.BankDest})
+	} else if strings.TrimSpace(detail.AccountIDDest) != "" && !banks.IsValidAccount(bank.Code, detail.AccountIDDest) {
+		fieldErrors = append(fieldErrors, FieldError{Field: "account_id_dest", Message: fmt.Sprintf("account number %s is not a valid %s account number", detail.AccountIDDest, bank.Code)})
+	}
+
+	if strings.TrimSpace(detail.AccountIDDest) == "" {
+		fieldErrors = append(fieldErrors, FieldError{Field: "account_id_dest", Message: "account number is required"})
+	}
+
+	if strings.TrimSpace(detail.AccountNameDest) == "" {
+		fieldErrors = append(fieldErrors, FieldError{Field: "account_name_dest", Message: "account name is required"})
+	}
+
+	if _, ok := CurrencyDecimals(detail.Currency); !ok {
+		fieldErrors = append(fieldErrors, FieldError{Field: "currency", Message: "unsupported currency " + detail.Currency})
+	}
+
+	return fieldErrors
+}
+
+// ValidateTransactionDetail checks a single transfer row and returns one
+// FieldError per problem found. The caller is responsible for setting Line.
+func ValidateTransactionDetail(detail TransactionDetail, banks *BankDirectory) []FieldError {
+	fieldErrors := ValidateBeneficiary(detail, banks)
+
+	if detail.Amount <= 0 {
+		fieldErrors = append(fieldErrors, FieldError{Field: "amount", Message: "amount must be greater than zero"})
+	} else if _, ok := CurrencyDecimals(detail.Currency); ok {
+		if err := ValidateCurrencyAmount(detail.Currency, detail.Amount); err != nil {
+			fieldErrors = append(fieldErrors, FieldError{Field: "amount", Message: err.Error()})
+		}
+	}
+
+	return fieldErrors
+}
+
+// DuplicateTransaction is a recent batch matching a new upload. Identical is
+// true when every row matches, false when only the beneficiaries match.
+type DuplicateTransaction struct {
+	TransactionID uuid.UUID
+	Identical     bool
+}
+
+type DuplicateTransactionResponse struct {
+	Message                string    `json:"message"`
+	DuplicateTransactionID uuid.UUID `json:"duplicate_transaction_id"`
 }
 
+// TransactionRepository reads and writes transactions of a single corporate
+// account. Every method is scoped by accountNumber and reports a transaction of
+// another corporate as ErrTransactionNotFound.
 type TransactionRepository interface {
-	GetTransactionSummary(ctx context.Context) (TransactionSummaryResult, error)
-	UpdateTransaction(ctx context.Context, trx Transaction) error
+	GetTransactionSummary(ctx context.Context, accountNumber string) (TransactionSummaryResult, error)
+	// UpdateTransactionStatus moves a transaction from event.PreviousStatus to
+	// event.NewStatus and appends the event to its history in the same DB
+	// transaction. It returns ErrTransactionNotFound for an unknown id and
+	// ErrInvalidTransition when the transaction is no longer in the previous
+	// status.
+	UpdateTransactionStatus(ctx context.Context, accountNumber string, event TransactionEvent) error
+	GetTransactionByID(ctx context.Context, accountNumber string, transactionID uuid.UUID) (Transaction, error)
+	// ApproveTransaction records one approval and its history event, and moves
+	// the transaction to approved once its required approvals are reached, in
+	// a single DB transaction. It returns ErrAlreadyApproved when the approver
+	// already approved this transaction.
+	ApproveTransaction(ctx context.Context, accountNumber string, approval TransactionApproval, event TransactionEvent) (ApprovalProgress, error)
+	GetTransactionEvents(ctx context.Context, accountNumber string, transactionID uuid.UUID) ([]TransactionEvent, error)
+	GetTransactionApprovals(ctx context.Context, accountNumber string, transactionID uuid.UUID) ([]TransactionApproval, error)
+	// GetRequiredApprovals returns the approvals needed by the strictest
+	// policy matching the total of any currency, or 1 when no policy matches.
+	GetRequiredApprovals(ctx context.Context, accountNumber string, totals []CurrencyTotal) (int, error)
 	GetTransactionList(ctx context.Context, param TransactionListParam) ([]Transaction, Pagination, error)
-	GetTransactionDetailByTransactionID(ctx context.Context, transactionID uuid.UUID) ([]TransactionDetail, error)
-	CreateTransaction(ctx context.Context, trx Transaction, trxDetails []TransactionDetail) error
+	GetTransactionDetailByTransactionID(ctx context.Context, accountNumber string, transactionID uuid.UUID) ([]TransactionDetail, error)
+	// GetTransactionAccountNumber returns the corporate of a batch in status,
+	// or ErrTransactionNotFound. It is not scoped to a corporate, so it is
+	// only used for bank staff.
+	GetTransactionAccountNumber(ctx context.Context, transactionID uuid.UUID, status TransactionStatus) (string, error)
+	CreateTransaction(ctx context.Context, trx Transaction, trxDetails []TransactionDetail, hits []ScreeningHit) error
+	FindRecentDuplicate(ctx context.Context, trx Transaction, since time.Time) (*DuplicateTransaction, error)
+	// TransitionScheduledTransactions moves every transaction, of any
+	// corporate, in status from with a transfer date before the given date to
+	// status to, recording event for each of them. It returns the ids of the
+	// moved transactions.
+	TransitionScheduledTransactions(ctx context.Context, from TransactionStatus, to TransactionStatus, before time.Time, event TransactionEvent) ([]uuid.UUID, error)
+	// GetTransactionsByStatus returns the transactions of every corporate in
+	// status, oldest first. It is meant for background jobs only.
+	GetTransactionsByStatus(ctx context.Context, status TransactionStatus) ([]Transaction, error)
+	// UpdateTransactionDetailStatus stores the execution result of a pending
+	// detail. It returns ErrInvalidTransition when the detail is no longer
+	// pending.
+	UpdateTransactionDetailStatus(ctx context.Context, detail TransactionDetail) error
+	// FinishTransaction rolls a processing transaction up to completed,
+	// partially_completed or failed from the status of its details and
+	// records event, filling in the new status. A transaction that still has
+	// pending details is left in processing.
+	FinishTransaction(ctx context.Context, accountNumber string, event TransactionEvent) (TransactionStatus, error)
+	// GetRetryTransactionIDs returns the batches created to retry the failed
+	// rows of a transaction, oldest first.
+	GetRetryTransactionIDs(ctx context.Context, accountNumber string, transactionID uuid.UUID) ([]uuid.UUID, error)
+	// GetPendingInquiries returns up to limit rows of batches waiting for
+	// approval whose account name has not been checked yet, oldest first.
+	GetPendingInquiries(ctx context.Context, limit int) ([]TransactionDetail, error)
+	// UpdateInquiryResult records the InquiryResult and InquiredAt of a
+	// pending inquiry.
+	UpdateInquiryResult(ctx context.Context, detail TransactionDetail) error
+	GetScreeningHits(ctx context.Context, accountNumber string, transactionID uuid.UUID) ([]ScreeningHit, error)
 }
@@ -12,6 +12,15 @@ type Role string
 const (
 	Maker    Role = "Maker"
 	Approver Role = "Approver"
+	Admin    Role = "Admin"
+	// Compliance users are bank staff who clear batches of any corporate held
+	// for compliance review. They are only provisioned.
+	Compliance Role = "Compliance"
+	// Operator users are bank staff running the platform, such as the bank
+	// directory shared by every corporate. They are only provisioned.
+	Operator Role = "Operator"
+	// System is the actor role of status changes made by background jobs.
+	System Role = "System"
 )
 
 type User struct {
@@ -57,6 +66,31 @@ type UserRegisterResponse struct {
 	Message string `json:"message"`
 }
 
+// CorporateUserRequest adds a user to the corporate of the admin creating it.
+// Users join an existing corporate only this way, self-registration always
+// starts a new corporate.
+type CorporateUserRequest struct {
+	UserID      string `json:"user_id" validate:"required"`
+	UserName    string `json:"user_name" validate:"required"`
+	Password    string `json:"password" validate:"required"`
+	Role        Role   `json:"role" validate:"required,eq=Maker|eq=Approver"`
+	PhoneNumber string `json:"phone_number" validate:"required,e164"`
+	Email       string `json:"email" validate:"required,email"`
+
+	AccountNumber string `json:"-"`
+	AdminUserID   string `json:"-"`
+	AdminRole     Role   `json:"-"`
+}
+
+func ValidateCorporateUserRequest(req *CorporateUserRequest) error {
+	validate := validator.New()
+	err := validate.Struct(req)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 type UserRegisterRequest struct {
 	AccountNumber string `json:"account_number" validate:"required"`
 	AccountName   string `json:"account_name" validate:"required"`
@@ -92,4 +126,5 @@ type UserRepository interface {
 	CreateUser(context.Context, User) error
 	GetUserByUserID(ctx context.Context, userID string) (*User, error)
 	UpdateUserLastLogin(ctx context.Context, userID string) error
+	GetUsersByAccountNumber(ctx context.Context, accountNumber string, role Role) ([]User, error)
 }
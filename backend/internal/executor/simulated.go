@@ -0,0 +1,55 @@
+package executor
+
+import (
+	"batch-transaction/internal/domain"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SimulatedExecutor pretends to send transfers, for local runs. Transfers to
+// account numbers starting with "999" fail as closed accounts, every other
+// transfer succeeds after Delay. Results are kept in memory by reference, so a
+// repeated transfer returns its first result without being sent again.
+type SimulatedExecutor struct {
+	Delay time.Duration
+
+	mu      sync.Mutex
+	results map[uuid.UUID]error
+}
+
+func NewSimulatedExecutor(delay time.Duration) *SimulatedExecutor {
+	return &SimulatedExecutor{
+		Delay:   delay,
+		results: map[uuid.UUID]error{},
+	}
+}
+
+func (e *SimulatedExecutor) Transfer(ctx context.Context, fromAccount string, detail domain.TransactionDetail) error {
+	e.mu.Lock()
+	result, sent := e.results[detail.ID]
+	e.mu.Unlock()
+	if sent {
+		return result
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(e.Delay):
+	}
+
+	if strings.HasPrefix(detail.AccountIDDest, "999") {
+		result = errors.New("beneficiary account is closed")
+	}
+
+	e.mu.Lock()
+	e.results[detail.ID] = result
+	e.mu.Unlock()
+
+	return result
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"batch-transaction/internal/domain"
+	"batch-transaction/internal/service"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type AccountHandler struct {
+	AccountService *service.AccountService
+}
+
+func NewAccountHandler(accountService *service.AccountService) *AccountHandler {
+	return &AccountHandler{
+		AccountService: accountService,
+	}
+}
+
+func (h *AccountHandler) AddLinkedAccount(w http.ResponseWriter, r *http.Request) {
+	var req domain.LinkedAccountRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx := r.Context()
+	req.AccountNumber = ctx.Value("account_number").(string)
+	req.UserID = ctx.Value("user_id").(string)
+	req.Role = domain.Role(ctx.Value("role").(string))
+
+	err = h.AccountService.AddLinkedAccount(ctx, req)
+	if _, ok := err.(validator.ValidationErrors); ok {
+		var fieldErrors []domain.FieldError
+		for _, err := range err.(validator.ValidationErrors) {
+			var message string
+			if err.Tag() == "required" {
+				message = fmt.Sprintf("%s is required", err.Field())
+			} else {
+				message = fmt.Sprintf("%s format invalid", err.Field())
+			}
+			fieldErrors = append(fieldErrors, domain.FieldError{
+				Field:   err.Field(),
+				Message: message,
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(domain.FieldErrorResponse{
+			Message: "Validation failed",
+			Errors:  fieldErrors,
+		})
+		return
+	}
+	if _, ok := err.(*domain.ForbiddenError); ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+	if err == domain.ErrLinkedAccountExists {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(domain.LinkedAccountResponse{
+		Message: "Linked account added successfully",
+	})
+}
+
+func (h *AccountHandler) GetLinkedAccounts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	accountNumber := ctx.Value("account_number").(string)
+	role := domain.Role(ctx.Value("role").(string))
+
+	result, err := h.AccountService.GetLinkedAccounts(ctx, accountNumber, role)
+	if _, ok := err.(*domain.ForbiddenError); ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+
+	resultData := []domain.LinkedAccount{}
+	if len(result) > 0 {
+		resultData = result
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(domain.LinkedAccountListResponse{
+		Data: resultData,
+	})
+}
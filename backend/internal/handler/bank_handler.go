@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"batch-transaction/internal/domain"
+	"batch-transaction/internal/service"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type BankHandler struct {
+	BankService *service.BankService
+}
+
+func NewBankHandler(bankService *service.BankService) *BankHandler {
+	return &BankHandler{
+		BankService: bankService,
+	}
+}
+
+func (h *BankHandler) GetBanks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	role := domain.Role(ctx.Value("role").(string))
+
+	result, err := h.BankService.GetBanks(ctx, role)
+	if _, ok := err.(*domain.ForbiddenError); ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+
+	resultData := []domain.Bank{}
+	if len(result) > 0 {
+		resultData = result
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(domain.BankListResponse{
+		Data: resultData,
+	})
+}
+
+func (h *BankHandler) AddBank(w http.ResponseWriter, r *http.Request) {
+	var req domain.BankRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx := r.Context()
+	req.Role = domain.Role(ctx.Value("role").(string))
+
+	err = h.BankService.AddBank(ctx, req)
+	if validationErr, ok := err.(*domain.ValidationError); ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(domain.FieldErrorResponse{
+			Message: validationErr.Message,
+			Errors:  validationErr.Errors,
+		})
+		return
+	}
+	if _, ok := err.(*domain.ForbiddenError); ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+	if err == domain.ErrBankExists {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(domain.BankResponse{
+		Message: "Bank added successfully",
+	})
+}
+
+func (h *BankHandler) UpdateBank(w http.ResponseWriter, r *http.Request) {
+	var req domain.BankRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx := r.Context()
+	req.Code = chi.URLParam(r, "code")
+	req.Role = domain.Role(ctx.Value("role").(string))
+
+	err = h.BankService.UpdateBank(ctx, req)
+	if validationErr, ok := err.(*domain.ValidationError); ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(domain.FieldErrorResponse{
+			Message: validationErr.Message,
+			Errors:  validationErr.Errors,
+		})
+		return
+	}
+	if _, ok := err.(*domain.ForbiddenError); ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+	if err == domain.ErrBankNotFound {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(domain.BankResponse{
+		Message: "Bank updated successfully",
+	})
+}
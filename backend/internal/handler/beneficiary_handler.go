@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"batch-transaction/internal/domain"
+	"batch-transaction/internal/service"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type BeneficiaryHandler struct {
+	BeneficiaryService *service.BeneficiaryService
+}
+
+func NewBeneficiaryHandler(beneficiaryService *service.BeneficiaryService) *BeneficiaryHandler {
+	return &BeneficiaryHandler{
+		BeneficiaryService: beneficiaryService,
+	}
+}
+
+func (h *BeneficiaryHandler) AddBeneficiary(w http.ResponseWriter, r *http.Request) {
+	var req domain.BeneficiaryRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx := r.Context()
+	req.AccountNumber = ctx.Value("account_number").(string)
+	req.UserID = ctx.Value("user_id").(string)
+	req.Role = domain.Role(ctx.Value("role").(string))
+
+	id, err := h.BeneficiaryService.AddBeneficiary(ctx, req)
+	if validationErr, ok := err.(*domain.ValidationError); ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(domain.FieldErrorResponse{
+			Message: validationErr.Message,
+			Errors:  validationErr.Errors,
+		})
+		return
+	}
+	if _, ok := err.(*domain.ForbiddenError); ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+	if err == domain.ErrBeneficiaryExists {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(domain.BeneficiaryResponse{
+		ID:      id,
+		Message: "Beneficiary added successfully",
+	})
+}
+
+func (h *BeneficiaryHandler) GetBeneficiaries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	accountNumber := ctx.Value("account_number").(string)
+
+	result, err := h.BeneficiaryService.GetBeneficiaries(ctx, accountNumber)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+
+	resultData := []domain.Beneficiary{}
+	if len(result) > 0 {
+		resultData = result
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(domain.BeneficiaryListResponse{
+		Data: resultData,
+	})
+}
+
+func (h *BeneficiaryHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	var req domain.TemplateRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx := r.Context()
+	req.AccountNumber = ctx.Value("account_number").(string)
+	req.UserID = ctx.Value("user_id").(string)
+	req.Role = domain.Role(ctx.Value("role").(string))
+
+	id, err := h.BeneficiaryService.CreateTemplate(ctx, req)
+	if validationErr, ok := err.(*domain.ValidationError); ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(domain.FieldErrorResponse{
+			Message: validationErr.Message,
+			Errors:  validationErr.Errors,
+		})
+		return
+	}
+	if _, ok := err.(*domain.ForbiddenError); ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+	if err == domain.ErrTemplateExists {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(domain.TemplateResponse{
+		ID:      id,
+		Message: "Template created successfully",
+	})
+}
+
+func (h *BeneficiaryHandler) GetTemplates(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	accountNumber := ctx.Value("account_number").(string)
+
+	result, err := h.BeneficiaryService.GetTemplates(ctx, accountNumber)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+
+	resultData := []domain.TransferTemplate{}
+	if len(result) > 0 {
+		resultData = result
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(domain.TemplateListResponse{
+		Data: resultData,
+	})
+}
+
+func (h *BeneficiaryHandler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	templateUUID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: "Invalid template ID",
+		})
+		return
+	}
+
+	ctx := r.Context()
+	accountNumber := ctx.Value("account_number").(string)
+	result, err := h.BeneficiaryService.GetTemplate(ctx, accountNumber, templateUUID)
+	if err == domain.ErrTemplateNotFound {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"batch-transaction/internal/domain"
+	"batch-transaction/internal/service"
+	"encoding/json"
+	"net/http"
+)
+
+type LimitHandler struct {
+	LimitService *service.LimitService
+}
+
+func NewLimitHandler(limitService *service.LimitService) *LimitHandler {
+	return &LimitHandler{
+		LimitService: limitService,
+	}
+}
+
+func (h *LimitHandler) GetTransferLimits(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	accountNumber := ctx.Value("account_number").(string)
+	role := domain.Role(ctx.Value("role").(string))
+
+	result, err := h.LimitService.GetTransferLimits(ctx, accountNumber, role)
+	if _, ok := err.(*domain.ForbiddenError); ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+
+	resultData := []domain.TransferLimit{}
+	if len(result) > 0 {
+		resultData = result
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(domain.TransferLimitListResponse{
+		Data: resultData,
+	})
+}
+
+func (h *LimitHandler) SaveTransferLimit(w http.ResponseWriter, r *http.Request) {
+	var req domain.TransferLimitRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx := r.Context()
+	req.AccountNumber = ctx.Value("account_number").(string)
+	req.Role = domain.Role(ctx.Value("role").(string))
+
+	err = h.LimitService.SaveTransferLimit(ctx, req)
+	if validationErr, ok := err.(*domain.ValidationError); ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(domain.FieldErrorResponse{
+			Message: validationErr.Message,
+			Errors:  validationErr.Errors,
+		})
+		return
+	}
+	if _, ok := err.(*domain.ForbiddenError); ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(domain.TransferLimitResponse{
+		Message: "Transfer limit saved successfully",
+	})
+}
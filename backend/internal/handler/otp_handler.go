@@ -4,17 +4,19 @@ import (
 	"batch-transaction/internal/domain"
 	"batch-transaction/internal/service"
 	"encoding/json"
+	"net"
 	"net/http"
 
 	"github.com/go-playground/validator/v10"
 )
 
 type OTPPayload struct {
-	Email string `json:"email" validate:"required,email"`
+	Email       string `json:"email" validate:"required,email"`
+	PhoneNumber string `json:"phone_number" validate:"omitempty,e164"`
 }
 
 type SendOTPResponse struct {
-	OTP     string `json:"otp"`
+	OTP     string `json:"otp,omitempty"`
 	Message string `json:"message"`
 }
 
@@ -61,7 +63,46 @@ func (h *OTPHandler) SendOTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	otpCode, err := h.OtpService.SendOTP(payload.Email)
+	clientIP, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		clientIP = r.RemoteAddr
+	}
+
+	recipient := domain.OTPRecipient{
+		Email:       payload.Email,
+		PhoneNumber: payload.PhoneNumber,
+	}
+	otpCode, err := h.OtpService.SendOTP(r.Context(), recipient, clientIP)
+	if err == domain.ErrOTPLocked || err == domain.ErrOTPRateLimited {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+	if err == domain.ErrOTPPhoneNumberRequired {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(domain.FieldErrorResponse{
+			Message: "Validation failed",
+			Errors: []domain.FieldError{
+				{
+					Field:   "phone_number",
+					Message: err.Error(),
+				},
+			},
+		})
+		return
+	}
+	if _, ok := err.(*domain.OTPDeliveryError); ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadGateway)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
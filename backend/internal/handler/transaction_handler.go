@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -23,7 +24,8 @@ func NewTransactionHandler(transactionService *service.TransactionService) *Tran
 
 func (h *TransactionHandler) GetTransactionSummary(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	result, err := h.TransactionService.GetTransactionSummary(ctx)
+	accountNumber := ctx.Value("account_number").(string)
+	result, err := h.TransactionService.GetTransactionSummary(ctx, accountNumber)
 	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -62,13 +64,161 @@ func (h *TransactionHandler) UpdateTransaction(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	transaction := domain.Transaction{
-		ID:                transactionUUID,
-		TransactionStatus: req.Status,
+	ctx := r.Context()
+	req.TransactionID = transactionUUID
+	req.UserID = ctx.Value("user_id").(string)
+	req.Role = domain.Role(ctx.Value("role").(string))
+	req.AccountNumber = ctx.Value("account_number").(string)
+	response, err := h.TransactionService.UpdateTransaction(ctx, req)
+	if _, ok := err.(*domain.ForbiddenError); ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+	if err == domain.ErrTransactionNotFound {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+	if limitErr, ok := err.(*domain.LimitExceededError); ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(domain.LimitExceededResponse{
+			Message:   limitErr.Error(),
+			Limit:     limitErr.Limit,
+			Scope:     limitErr.Scope,
+			Currency:  limitErr.Currency,
+			Amount:    limitErr.Amount,
+			Remaining: limitErr.Remaining,
+			Line:      limitErr.Line,
+		})
+		return
+	}
+	if err == domain.ErrInvalidTransition || err == domain.ErrAlreadyApproved || err == domain.ErrTransferDateExpired ||
+		err == domain.ErrInquiryMismatch || err == domain.ErrInquiryPending {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+	if err == domain.ErrInvalidTransactionStatus {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+	if err == domain.ErrRejectionReasonRequired || err == domain.ErrClearanceReasonRequired {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(domain.FieldErrorResponse{
+			Message: "Validation failed",
+			Errors: []domain.FieldError{
+				{
+					Field:   "reason",
+					Message: err.Error(),
+				},
+			},
+		})
+		return
+	}
+	if err == domain.ErrOTPRequired || err == domain.ErrInvalidOTP {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(domain.FieldErrorResponse{
+			Message: "Validation failed",
+			Errors: []domain.FieldError{
+				{
+					Field:   "otp_code",
+					Message: err.Error(),
+				},
+			},
+		})
+		return
+	}
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *TransactionHandler) SendApprovalOTP(w http.ResponseWriter, r *http.Request) {
+	transactionID := chi.URLParam(r, "id")
+	transactionUUID, err := uuid.Parse(transactionID)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: "Invalid transaction ID",
+		})
+		return
 	}
 
 	ctx := r.Context()
-	err = h.TransactionService.UpdateTransaction(ctx, transaction)
+	req := domain.ApprovalOTPRequest{
+		TransactionID: transactionUUID,
+		UserID:        ctx.Value("user_id").(string),
+		Role:          domain.Role(ctx.Value("role").(string)),
+		AccountNumber: ctx.Value("account_number").(string),
+	}
+	response, err := h.TransactionService.SendApprovalOTP(ctx, req)
+	if _, ok := err.(*domain.ForbiddenError); ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+	if err == domain.ErrTransactionNotFound {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+	if err == domain.ErrInvalidTransition {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+	if err == domain.ErrOTPRateLimited {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+	if _, ok := err.(*domain.OTPDeliveryError); ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadGateway)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -80,9 +230,7 @@ func (h *TransactionHandler) UpdateTransaction(w http.ResponseWriter, r *http.Re
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(domain.UpdateTransactionResponse{
-		Message: "Transaction updated successfully",
-	})
+	json.NewEncoder(w).Encode(response)
 }
 
 func (h *TransactionHandler) GetTransactionList(w http.ResponseWriter, r *http.Request) {
@@ -102,13 +250,15 @@ func (h *TransactionHandler) GetTransactionList(w http.ResponseWriter, r *http.R
 		statusFilterList = append(statusFilterList, domain.TransactionStatus(status))
 	}
 
+	ctx := r.Context()
 	param := domain.TransactionListParam{
-		Page:         pageInt,
-		PerPage:      perPageInt,
-		StatusFilter: statusFilterList,
+		AccountNumber: ctx.Value("account_number").(string),
+		Role:          domain.Role(ctx.Value("role").(string)),
+		Page:          pageInt,
+		PerPage:       perPageInt,
+		StatusFilter:  statusFilterList,
 	}
 
-	ctx := r.Context()
 	result, paging, err := h.TransactionService.GetTransactionList(ctx, param)
 	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
@@ -145,7 +295,17 @@ func (h *TransactionHandler) GetTransactionDetail(w http.ResponseWriter, r *http
 	}
 
 	ctx := r.Context()
-	result, err := h.TransactionService.GetTransactionDetail(ctx, transactionUUID)
+	accountNumber := ctx.Value("account_number").(string)
+	role := domain.Role(ctx.Value("role").(string))
+	result, err := h.TransactionService.GetTransactionDetail(ctx, accountNumber, role, transactionUUID)
+	if err == domain.ErrTransactionNotFound {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -157,8 +317,50 @@ func (h *TransactionHandler) GetTransactionDetail(w http.ResponseWriter, r *http
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(domain.TransactionDetailResponse{
-		Data: result,
+	json.NewEncoder(w).Encode(result)
+}
+
+func (h *TransactionHandler) GetTransactionHistory(w http.ResponseWriter, r *http.Request) {
+	transactionID := chi.URLParam(r, "id")
+	transactionUUID, err := uuid.Parse(transactionID)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: domain.ErrInvalidTransactionID.Error(),
+		})
+		return
+	}
+
+	ctx := r.Context()
+	accountNumber := ctx.Value("account_number").(string)
+	role := domain.Role(ctx.Value("role").(string))
+	result, err := h.TransactionService.GetTransactionHistory(ctx, accountNumber, role, transactionUUID)
+	if err == domain.ErrTransactionNotFound {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+
+	resultData := []domain.TransactionEvent{}
+	if len(result) > 0 {
+		resultData = result
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(domain.TransactionHistoryResponse{
+		Data: resultData,
 	})
 }
 
@@ -166,7 +368,84 @@ func (h *TransactionHandler) CreateTransaction(w http.ResponseWriter, r *http.Re
 
 	r.ParseMultipartForm(10 << 20)
 
-	file, _, err := r.FormFile("file")
+	var req domain.TransactionUploadRequest
+	if templateID := r.FormValue("template_id"); templateID != "" {
+		templateUUID, err := uuid.Parse(templateID)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(domain.FieldErrorResponse{
+				Message: "Validation failed",
+				Errors: []domain.FieldError{
+					{
+						Field:   "template_id",
+						Message: "template_id must be a valid id",
+					},
+				},
+			})
+			return
+		}
+		req.TemplateID = &templateUUID
+
+		if amounts := r.FormValue("amounts"); amounts != "" {
+			err = json.Unmarshal([]byte(amounts), &req.Amounts)
+			if err != nil {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(domain.FieldErrorResponse{
+					Message: "Validation failed",
+					Errors: []domain.FieldError{
+						{
+							Field:   "amounts",
+							Message: "amounts must map beneficiary ids to amounts: " + err.Error(),
+						},
+					},
+				})
+				return
+			}
+		}
+	} else {
+		file, fileHeader, err := r.FormFile("file")
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+				Message: err.Error(),
+			})
+			return
+		}
+		defer file.Close()
+
+		req.File = &file
+		req.FileName = fileHeader.Filename
+		req.ContentType = fileHeader.Header.Get("Content-Type")
+	}
+
+	// a template batch may leave its totals to be computed from the template
+	optionalTotals := req.TemplateID != nil && r.FormValue("total_amount") == "" && r.FormValue("total_record") == ""
+
+	var err error
+	if !optionalTotals {
+		req.TotalAmount, err = domain.ParseMoney(r.FormValue("total_amount"), domain.MoneyMaxDecimals)
+	}
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(domain.FieldErrorResponse{
+			Message: "Validation failed",
+			Errors: []domain.FieldError{
+				{
+					Field:   "total_amount",
+					Message: err.Error(),
+				},
+			},
+		})
+		return
+	}
+
+	if !optionalTotals {
+		req.TotalRecord, err = strconv.Atoi(r.FormValue("total_record"))
+	}
 	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -175,19 +454,111 @@ func (h *TransactionHandler) CreateTransaction(w http.ResponseWriter, r *http.Re
 		})
 		return
 	}
-	defer file.Close()
 
-	totalAmount, err := strconv.ParseFloat(r.FormValue("total_amount"), 64)
+	currencyTotals, err := domain.ParseCurrencyTotals(r.FormValue("currency_totals"))
 	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(domain.FieldErrorResponse{
+			Message: "Validation failed",
+			Errors: []domain.FieldError{
+				{
+					Field:   "currency_totals",
+					Message: err.Error(),
+				},
+			},
+		})
+		return
+	}
+
+	var transferDate time.Time
+	if value := r.FormValue("transfer_date"); value != "" {
+		transferDate, err = time.Parse(domain.TransferDateLayout, value)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(domain.FieldErrorResponse{
+				Message: "Validation failed",
+				Errors: []domain.FieldError{
+					{
+						Field:   "transfer_date",
+						Message: "transfer_date must be formatted as YYYY-MM-DD",
+					},
+				},
+			})
+			return
+		}
+	}
+
+	req.Currency = domain.NormalizeCurrency(r.FormValue("currency"), domain.DefaultCurrency)
+	req.CurrencyTotals = currencyTotals
+	req.TransferDate = transferDate
+	req.FromAccount = r.FormValue("from_account")
+	req.UserID = r.Context().Value("user_id").(string)
+	req.Role = domain.Role(r.Context().Value("role").(string))
+	req.AccountNumber = r.Context().Value("account_number").(string)
+	req.IdempotencyKey = r.Header.Get("Idempotency-Key")
+	req.ConfirmDuplicate, _ = strconv.ParseBool(r.FormValue("confirm_duplicate"))
+	req.MixedCurrency, _ = strconv.ParseBool(r.FormValue("mixed_currency"))
+
+	ctx := r.Context()
+	response, err := h.TransactionService.CreateTransaction(ctx, req)
+	if validationErr, ok := err.(*domain.ValidationError); ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(domain.FieldErrorResponse{
+			Message: validationErr.Message,
+			Errors:  validationErr.Errors,
+		})
+		return
+	}
+	if _, ok := err.(*domain.ForbiddenError); ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+	if limitErr, ok := err.(*domain.LimitExceededError); ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(domain.LimitExceededResponse{
+			Message:   limitErr.Error(),
+			Limit:     limitErr.Limit,
+			Scope:     limitErr.Scope,
+			Currency:  limitErr.Currency,
+			Amount:    limitErr.Amount,
+			Remaining: limitErr.Remaining,
+			Line:      limitErr.Line,
+		})
+		return
+	}
+	if duplicateErr, ok := err.(*domain.DuplicateTransactionError); ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(domain.DuplicateTransactionResponse{
+			Message:                duplicateErr.Error() + ", set confirm_duplicate=true to upload it anyway",
+			DuplicateTransactionID: duplicateErr.TransactionID,
+		})
+		return
+	}
+	if err == domain.ErrInvalidIdempotencyKey {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+	if err == domain.ErrIdempotencyKeyConflict || err == domain.ErrIdempotencyKeyInProgress {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
 		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
 			Message: err.Error(),
 		})
 		return
 	}
-
-	totalRecord, err := strconv.Atoi(r.FormValue("total_record"))
 	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -197,19 +568,80 @@ func (h *TransactionHandler) CreateTransaction(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	fromAccount := r.FormValue("from_account")
-	userID := r.Context().Value("user_id").(string)
+	if response.Replayed {
+		w.Header().Set("Idempotent-Replayed", "true")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
 
-	req := domain.TransactionUploadRequest{
-		File:        &file,
-		TotalAmount: totalAmount,
-		TotalRecord: totalRecord,
-		FromAccount: fromAccount,
-		UserID:      userID,
+func (h *TransactionHandler) RetryFailedTransaction(w http.ResponseWriter, r *http.Request) {
+	transactionID := chi.URLParam(r, "id")
+	transactionUUID, err := uuid.Parse(transactionID)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: domain.ErrInvalidTransactionID.Error(),
+		})
+		return
 	}
 
 	ctx := r.Context()
-	err = h.TransactionService.CreateTransaction(ctx, req)
+	req := domain.RetryTransactionRequest{
+		TransactionID: transactionUUID,
+		UserID:        ctx.Value("user_id").(string),
+		Role:          domain.Role(ctx.Value("role").(string)),
+		AccountNumber: ctx.Value("account_number").(string),
+	}
+	response, err := h.TransactionService.RetryFailedTransaction(ctx, req)
+	if _, ok := err.(*domain.ForbiddenError); ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+	if err == domain.ErrTransactionNotFound {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+	if err == domain.ErrInvalidTransition {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: "only failed transfers of a partially completed or failed transaction can be retried",
+		})
+		return
+	}
+	if limitErr, ok := err.(*domain.LimitExceededError); ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(domain.LimitExceededResponse{
+			Message:   limitErr.Error(),
+			Limit:     limitErr.Limit,
+			Scope:     limitErr.Scope,
+			Currency:  limitErr.Currency,
+			Amount:    limitErr.Amount,
+			Remaining: limitErr.Remaining,
+			Line:      limitErr.Line,
+		})
+		return
+	}
+	if err == domain.ErrRetryExists {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -221,9 +653,5 @@ func (h *TransactionHandler) CreateTransaction(w http.ResponseWriter, r *http.Re
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(domain.TransactionUploadResponse{
-		TotalRecord: totalRecord,
-		TotalAmount: totalAmount,
-		Message:     "Transaction created successfully",
-	})
+	json.NewEncoder(w).Encode(response)
 }
@@ -58,6 +58,22 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	if err == domain.ErrOTPLocked {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+	if err == domain.ErrorUserAlreadyExist {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
 	if err == domain.ErrInvalidOTP {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusBadRequest)
@@ -88,6 +104,79 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (h *UserHandler) CreateCorporateUser(w http.ResponseWriter, r *http.Request) {
+	var req domain.CorporateUserRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx := r.Context()
+	req.AccountNumber = ctx.Value("account_number").(string)
+	req.AdminUserID = ctx.Value("user_id").(string)
+	req.AdminRole = domain.Role(ctx.Value("role").(string))
+	err = h.UserService.CreateCorporateUser(ctx, req)
+	if _, ok := err.(validator.ValidationErrors); ok {
+		var fieldErrors []domain.FieldError
+		for _, err := range err.(validator.ValidationErrors) {
+			var message string
+			if err.Tag() == "required" {
+				message = fmt.Sprintf("%s is required", err.Field())
+			} else {
+				message = fmt.Sprintf("%s format invalid", err.Field())
+			}
+			fieldErrors = append(fieldErrors, domain.FieldError{
+				Field:   err.Field(),
+				Message: message,
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(domain.FieldErrorResponse{
+			Message: "Validation failed",
+			Errors:  fieldErrors,
+		})
+		return
+	}
+	if _, ok := err.(*domain.ForbiddenError); ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+	if err == domain.ErrorUserAlreadyExist {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(domain.CommonErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(domain.UserRegisterResponse{
+		Message: "User created successfully",
+	})
+}
+
 func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var req domain.UserLogin
 
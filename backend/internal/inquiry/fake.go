@@ -0,0 +1,39 @@
+package inquiry
+
+import (
+	"batch-transaction/internal/domain"
+	"context"
+	"strings"
+	"time"
+)
+
+// FakeInquiry answers account name inquiries without asking a bank, for local
+// runs. Account numbers starting with "000" are unknown to the bank and those
+// starting with "888" are held by someone else, so they never match. Every
+// other account matches. Answers take Delay and depend only on the row.
+type FakeInquiry struct {
+	Delay time.Duration
+}
+
+func NewFakeInquiry(delay time.Duration) *FakeInquiry {
+	return &FakeInquiry{
+		Delay: delay,
+	}
+}
+
+func (f *FakeInquiry) Inquire(ctx context.Context, detail domain.TransactionDetail) (domain.InquiryResult, error) {
+	select {
+	case <-ctx.Done():
+		return domain.InquiryPending, ctx.Err()
+	case <-time.After(f.Delay):
+	}
+
+	switch {
+	case strings.HasPrefix(detail.AccountIDDest, "000"):
+		return domain.InquiryUnknown, nil
+	case strings.HasPrefix(detail.AccountIDDest, "888"):
+		return domain.InquiryMismatch, nil
+	default:
+		return domain.InquiryMatch, nil
+	}
+}
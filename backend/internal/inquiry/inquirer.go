@@ -0,0 +1,97 @@
+package inquiry
+
+import (
+	"batch-transaction/internal/config"
+	"batch-transaction/internal/domain"
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	null "gopkg.in/guregu/null.v4"
+)
+
+// batchSize is how many rows are checked per poll.
+const batchSize = 100
+
+// Inquirer checks the account names of the rows of batches waiting for
+// approval through a domain.BeneficiaryInquiry, in the background after
+// upload, and records a result per row.
+type Inquirer struct {
+	transactionRepo domain.TransactionRepository
+	inquiry         domain.BeneficiaryInquiry
+	configInterface config.ConfigInterface
+	log             *log.Logger
+}
+
+func NewInquirer(transactionRepo domain.TransactionRepository, inquiry domain.BeneficiaryInquiry, configInterface config.ConfigInterface, log *log.Logger) *Inquirer {
+	return &Inquirer{
+		transactionRepo: transactionRepo,
+		inquiry:         inquiry,
+		configInterface: configInterface,
+		log:             log,
+	}
+}
+
+// Run checks pending rows every INQUIRY_INTERVAL until ctx is done.
+func (i *Inquirer) Run(ctx context.Context) {
+	ticker := time.NewTicker(i.configInterface.GetInquiryInterval())
+	defer ticker.Stop()
+
+	for {
+		i.Poll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll checks pending rows until none are left or ctx is done.
+func (i *Inquirer) Poll(ctx context.Context) {
+	for ctx.Err() == nil {
+		details, err := i.transactionRepo.GetPendingInquiries(ctx, batchSize)
+		if err != nil {
+			i.log.Println("inquirer: get pending inquiries:", err)
+			return
+		}
+
+		for _, detail := range details {
+			if !i.inquire(ctx, detail) {
+				return
+			}
+		}
+		if len(details) < batchSize {
+			return
+		}
+	}
+}
+
+// inquire records the result of a single row and reports whether the next
+// row may be checked.
+func (i *Inquirer) inquire(ctx context.Context, detail domain.TransactionDetail) bool {
+	result, err := i.inquiry.Inquire(ctx, detail)
+	if ctx.Err() != nil {
+		// checked again on the next run
+		return false
+	}
+	if err == nil && result != domain.InquiryMatch && result != domain.InquiryMismatch && result != domain.InquiryUnknown {
+		err = fmt.Errorf("unexpected result %q", result)
+	}
+	if err != nil {
+		i.log.Println("inquirer: inquire", detail.ID, err)
+		result = domain.InquiryUnknown
+	}
+
+	detail.InquiryResult = result
+	detail.InquiredAt = null.TimeFrom(time.Now())
+	err = i.transactionRepo.UpdateInquiryResult(ctx, detail)
+	if err != nil {
+		i.log.Println("inquirer: update inquiry result", detail.ID, err)
+		return false
+	}
+
+	return true
+}
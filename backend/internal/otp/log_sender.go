@@ -0,0 +1,33 @@
+package otp
+
+import (
+	"batch-transaction/internal/domain"
+	"context"
+	"log"
+	"os"
+)
+
+// LogSender writes one-time codes to a log instead of delivering them, for
+// local runs.
+type LogSender struct {
+	log *log.Logger
+}
+
+// NewLogSender appends codes to the file at path, or writes them to the
+// standard logger when path is empty.
+func NewLogSender(path string) (*LogSender, error) {
+	if path == "" {
+		return &LogSender{log: log.Default()}, nil
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &LogSender{log: log.New(file, "", log.LstdFlags)}, nil
+}
+
+func (s *LogSender) SendOTP(ctx context.Context, recipient domain.OTPRecipient, purpose string, code string) error {
+	s.log.Printf("otp for email=%q phone_number=%q: %s", recipient.Email, recipient.PhoneNumber, message(purpose, code))
+	return nil
+}
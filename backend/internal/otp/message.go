@@ -0,0 +1,8 @@
+package otp
+
+import "fmt"
+
+// message is the text delivered with a one-time code.
+func message(purpose string, code string) string {
+	return fmt.Sprintf("Your one-time code for %s is %s. Do not share it with anyone.", purpose, code)
+}
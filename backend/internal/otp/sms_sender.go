@@ -0,0 +1,68 @@
+package otp
+
+import (
+	"batch-transaction/internal/domain"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// SMSSender delivers one-time codes by SMS through an HTTP gateway. It posts
+// {"to": phone number, "message": text} as JSON to URL, with APIKey as a
+// bearer token, and treats any status other than 2xx as a failure.
+type SMSSender struct {
+	URL    string
+	APIKey string
+
+	client *http.Client
+}
+
+func NewSMSSender(url string, apiKey string) *SMSSender {
+	return &SMSSender{
+		URL:    url,
+		APIKey: apiKey,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type smsRequest struct {
+	To      string `json:"to"`
+	Message string `json:"message"`
+}
+
+func (s *SMSSender) SendOTP(ctx context.Context, recipient domain.OTPRecipient, purpose string, code string) error {
+	if recipient.PhoneNumber == "" {
+		return domain.ErrOTPPhoneNumberRequired
+	}
+
+	payload, err := json.Marshal(smsRequest{
+		To:      recipient.PhoneNumber,
+		Message: message(purpose, code),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.APIKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("sms gateway returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package otp
+
+import (
+	"batch-transaction/internal/domain"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTPSender delivers one-time codes by email through an SMTP server. The
+// connection is upgraded with STARTTLS when the server offers it, and the
+// server is only authenticated against when Username is set.
+type SMTPSender struct {
+	Addr     string
+	Username string
+	Password string
+	From     string
+	Timeout  time.Duration
+}
+
+func NewSMTPSender(addr string, username string, password string, from string) *SMTPSender {
+	return &SMTPSender{
+		Addr:     addr,
+		Username: username,
+		Password: password,
+		From:     from,
+		Timeout:  10 * time.Second,
+	}
+}
+
+func (s *SMTPSender) SendOTP(ctx context.Context, recipient domain.OTPRecipient, purpose string, code string) error {
+	if recipient.Email == "" {
+		return domain.ErrOTPEmailRequired
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	host, _, err := net.SplitHostPort(s.Addr)
+	if err != nil {
+		return err
+	}
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		err = client.StartTLS(&tls.Config{ServerName: host})
+		if err != nil {
+			return err
+		}
+	}
+	if s.Username != "" {
+		err = client.Auth(smtp.PlainAuth("", s.Username, s.Password, host))
+		if err != nil {
+			return err
+		}
+	}
+
+	err = client.Mail(s.From)
+	if err != nil {
+		return err
+	}
+	err = client.Rcpt(recipient.Email)
+	if err != nil {
+		return err
+	}
+
+	body, err := client.Data()
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(body, "From: %s\r\nTo: %s\r\nSubject: Your one-time code\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		s.From, recipient.Email, strings.ReplaceAll(message(purpose, code), "\n", "\r\n"))
+	if err != nil {
+		body.Close()
+		return err
+	}
+	err = body.Close()
+	if err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"batch-transaction/internal/domain"
+	"encoding/csv"
+	"io"
+)
+
+type CSVParser struct {
+	opts Options
+}
+
+func NewCSVParser(opts Options) *CSVParser {
+	return &CSVParser{
+		opts: opts,
+	}
+}
+
+// Parse reads a comma separated file whose first line is the header. Columns
+// are located by header name, so they may appear in any order.
+func (p *CSVParser) Parse(file io.Reader) ([]domain.TransactionDetail, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, invalidFile([]domain.FieldError{{Field: "file", Message: "file is empty"}})
+	} else if parseErr, ok := err.(*csv.ParseError); ok {
+		return nil, invalidFile([]domain.FieldError{{
+			Line:    parseErr.Line,
+			Field:   "file",
+			Message: parseErr.Err.Error(),
+		}})
+	} else if err != nil {
+		return nil, err
+	}
+
+	columns, fieldErrors := mapColumns(header, p.opts.ColumnAliases)
+	if len(fieldErrors) > 0 {
+		return nil, invalidFile(fieldErrors)
+	}
+
+	var rows []row
+	for {
+		line, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if parseErr, ok := err.(*csv.ParseError); ok {
+			return nil, invalidFile([]domain.FieldError{{
+				Line:    parseErr.Line,
+				Field:   "file",
+				Message: parseErr.Err.Error(),
+			}})
+		} else if err != nil {
+			return nil, err
+		}
+
+		lineNumber, _ := reader.FieldPos(0)
+		rows = append(rows, row{line: lineNumber, values: line})
+	}
+
+	return parseRows(rows, columns, p.opts)
+}
@@ -0,0 +1,144 @@
+package parser
+
+import (
+	"batch-transaction/internal/domain"
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FixedWidthField is one column of a fixed-width layout. Start is the 1-based
+// character position of the column, as written in bank file specifications.
+type FixedWidthField struct {
+	Column string
+	Start  int
+	Length int
+}
+
+type FixedWidthParser struct {
+	fields  []FixedWidthField
+	columns map[string]int
+	opts    Options
+}
+
+// NewFixedWidthParser builds a parser from opts.FixedWidthLayout, formatted as
+// "column:start:length,column:start:length", for example
+// "bank_dest:1:10,account_id_dest:11:20,account_name_dest:31:40,amount:71:15".
+func NewFixedWidthParser(opts Options) (*FixedWidthParser, error) {
+	fields, err := ParseFixedWidthLayout(opts.FixedWidthLayout)
+	if err != nil {
+		return nil, invalidLayoutError(err)
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Start < fields[j].Start
+	})
+
+	columns := map[string]int{}
+	for i, field := range fields {
+		columns[field.Column] = i
+	}
+	for _, column := range requiredColumns {
+		if _, ok := columns[column]; !ok {
+			return nil, invalidLayoutError(fmt.Errorf("fixed-width layout is missing column %s", column))
+		}
+	}
+
+	return &FixedWidthParser{
+		fields:  fields,
+		columns: columns,
+		opts:    opts,
+	}, nil
+}
+
+// invalidLayoutError reports a missing or invalid layout as a rejected upload,
+// since no fixed-width file can be read with it.
+func invalidLayoutError(err error) error {
+	return &domain.ValidationError{
+		Message: "Invalid transaction file",
+		Errors: []domain.FieldError{{
+			Field:   "file",
+			Message: err.Error(),
+		}},
+	}
+}
+
+func ParseFixedWidthLayout(layout string) ([]FixedWidthField, error) {
+	if strings.TrimSpace(layout) == "" {
+		return nil, fmt.Errorf("fixed-width layout is not configured")
+	}
+
+	var fields []FixedWidthField
+	for _, entry := range strings.Split(layout, ",") {
+		parts := strings.Split(strings.TrimSpace(entry), ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid fixed-width layout entry %q", entry)
+		}
+
+		start, err := strconv.Atoi(parts[1])
+		if err != nil || start < 1 {
+			return nil, fmt.Errorf("invalid start position in fixed-width layout entry %q", entry)
+		}
+		length, err := strconv.Atoi(parts[2])
+		if err != nil || length < 1 {
+			return nil, fmt.Errorf("invalid length in fixed-width layout entry %q", entry)
+		}
+
+		fields = append(fields, FixedWidthField{
+			Column: strings.TrimSpace(parts[0]),
+			Start:  start,
+			Length: length,
+		})
+	}
+
+	return fields, nil
+}
+
+// Parse reads one transfer per line. Blank lines are skipped and a line that
+// ends before a column starts is reported as missing that column.
+func (p *FixedWidthParser) Parse(file io.Reader) ([]domain.TransactionDetail, error) {
+	scanner := bufio.NewScanner(file)
+
+	var rows []row
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := []rune(strings.TrimRight(scanner.Text(), "\r"))
+		if strings.TrimSpace(string(line)) == "" {
+			continue
+		}
+
+		var values []string
+		for _, field := range p.fields {
+			start := field.Start - 1
+			if start >= len(line) {
+				break
+			}
+			end := start + field.Length
+			if end > len(line) {
+				end = len(line)
+			}
+			values = append(values, string(line[start:end]))
+		}
+
+		rows = append(rows, row{line: lineNumber, values: values})
+	}
+	if err := scanner.Err(); err == bufio.ErrTooLong {
+		return nil, invalidFile([]domain.FieldError{{
+			Line:    lineNumber + 1,
+			Field:   "file",
+			Message: "line is too long",
+		}})
+	} else if err != nil {
+		return nil, err
+	}
+
+	if lineNumber == 0 {
+		return nil, invalidFile([]domain.FieldError{{Field: "file", Message: "file is empty"}})
+	}
+
+	return parseRows(rows, p.columns, p.opts)
+}
@@ -0,0 +1,274 @@
+package parser
+
+import (
+	"batch-transaction/internal/domain"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	null "gopkg.in/guregu/null.v4"
+)
+
+// TransactionParser turns an uploaded batch file into transfer rows. Every
+// format returns the same domain.TransactionDetail slice, and row problems are
+// reported as a *domain.ValidationError.
+type TransactionParser interface {
+	Parse(file io.Reader) ([]domain.TransactionDetail, error)
+}
+
+type Options struct {
+	ColumnAliases    map[string][]string
+	FixedWidthLayout string
+	// Currency is used for rows that do not name their own currency.
+	Currency string
+	// ValidateTransferDate, when set, checks the transfer_date of every row
+	// that has one.
+	ValidateTransferDate func(date time.Time) error
+	// Banks resolves the bank of every row. Bank names and SWIFT/BIC codes
+	// are replaced by the bank code.
+	Banks *domain.BankDirectory
+}
+
+const (
+	contentTypeCSV  = "text/csv"
+	contentTypeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	contentTypeText = "text/plain"
+)
+
+// NewTransactionParser picks a parser from the file extension, falling back to
+// the content type when the extension is not recognised.
+func NewTransactionParser(fileName string, contentType string, opts Options) (TransactionParser, error) {
+	if opts.Currency == "" {
+		opts.Currency = domain.DefaultCurrency
+	}
+
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return NewCSVParser(opts), nil
+	case ".xlsx":
+		return NewXLSXParser(opts), nil
+	case ".txt", ".dat":
+		return NewFixedWidthParser(opts)
+	}
+
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	switch strings.TrimSpace(strings.ToLower(mediaType)) {
+	case contentTypeCSV, "application/csv":
+		return NewCSVParser(opts), nil
+	case contentTypeXLSX:
+		return NewXLSXParser(opts), nil
+	case contentTypeText:
+		return NewFixedWidthParser(opts)
+	}
+
+	return nil, &domain.ValidationError{
+		Message: "Invalid transaction file",
+		Errors: []domain.FieldError{{
+			Field:   "file",
+			Message: fmt.Sprintf("unsupported file format %q, use CSV, XLSX or fixed-width text", fileName),
+		}},
+	}
+}
+
+// columnAliases maps each upload column to the header names accepted for it.
+// The first alias of every column matches the bundled CSV template.
+var columnAliases = map[string][]string{
+	"bank_dest":         {"to_bank_name", "bank"},
+	"account_id_dest":   {"to_account_no", "account_no", "account_number"},
+	"account_name_dest": {"to_account_name", "account_name"},
+	"amount":            {"transfer_amount"},
+	"description":       {"remark", "note"},
+	"transfer_date":     {"date"},
+	"currency":          {"ccy", "currency_code"},
+}
+
+var requiredColumns = []string{"bank_dest", "account_id_dest", "account_name_dest", "amount"}
+
+var transferDateLayouts = []string{"2006-01-02", time.RFC3339}
+
+// row is one line of a tabular file together with its 1-based line number.
+type row struct {
+	line   int
+	values []string
+}
+
+// parseRows converts already-mapped rows into transaction details, collecting
+// every row problem instead of stopping at the first one. Rows without a
+// currency column value are in opts.Currency.
+func parseRows(rows []row, columns map[string]int, opts Options) ([]domain.TransactionDetail, error) {
+	var transactionDetails []domain.TransactionDetail
+	var fieldErrors []domain.FieldError
+
+	for _, r := range rows {
+		transactionDetail, rowErrors := parseRow(r.values, columns, opts)
+		transactionDetail.Line = r.line
+		for _, rowError := range rowErrors {
+			rowError.Line = r.line
+			fieldErrors = append(fieldErrors, rowError)
+		}
+		transactionDetails = append(transactionDetails, transactionDetail)
+	}
+
+	if len(fieldErrors) == 0 && len(transactionDetails) == 0 {
+		fieldErrors = append(fieldErrors, domain.FieldError{Field: "file", Message: "file has no transaction rows"})
+	}
+
+	if len(fieldErrors) > 0 {
+		return nil, invalidFile(fieldErrors)
+	}
+
+	return transactionDetails, nil
+}
+
+func invalidFile(fieldErrors []domain.FieldError) *domain.ValidationError {
+	return &domain.ValidationError{
+		Message: "Invalid transaction file",
+		Errors:  fieldErrors,
+	}
+}
+
+// mapColumns resolves the header row to a column index for every known
+// column, using the column name itself, the built-in aliases and any extra
+// aliases from config.
+func mapColumns(header []string, extraAliases map[string][]string) (map[string]int, []domain.FieldError) {
+	names := map[string]string{}
+	for column, aliases := range columnAliases {
+		names[column] = column
+		for _, alias := range aliases {
+			names[normalizeHeader(alias)] = column
+		}
+	}
+	for column, aliases := range extraAliases {
+		for _, alias := range aliases {
+			names[normalizeHeader(alias)] = column
+		}
+	}
+
+	var fieldErrors []domain.FieldError
+	columns := map[string]int{}
+	for i, name := range header {
+		column, ok := names[normalizeHeader(name)]
+		if !ok {
+			continue
+		}
+		if _, duplicate := columns[column]; duplicate {
+			fieldErrors = append(fieldErrors, domain.FieldError{
+				Line:    1,
+				Field:   column,
+				Message: fmt.Sprintf("column %q is mapped more than once", name),
+			})
+			continue
+		}
+		columns[column] = i
+	}
+
+	for _, column := range requiredColumns {
+		if _, ok := columns[column]; !ok {
+			fieldErrors = append(fieldErrors, domain.FieldError{
+				Line:    1,
+				Field:   column,
+				Message: "missing column in header",
+			})
+		}
+	}
+
+	return columns, fieldErrors
+}
+
+func normalizeHeader(name string) string {
+	name = strings.TrimPrefix(name, "\ufeff")
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.Join(strings.Fields(name), "_")
+}
+
+func parseRow(line []string, columns map[string]int, opts Options) (domain.TransactionDetail, []domain.FieldError) {
+	var fieldErrors []domain.FieldError
+
+	value := func(column string) (string, bool) {
+		i, ok := columns[column]
+		if !ok || i >= len(line) {
+			return "", false
+		}
+		return strings.TrimSpace(line[i]), true
+	}
+
+	for _, column := range requiredColumns {
+		if _, ok := value(column); !ok {
+			fieldErrors = append(fieldErrors, domain.FieldError{
+				Field:   column,
+				Message: fmt.Sprintf("missing column, expected at least %d columns but got %d", columns[column]+1, len(line)),
+			})
+		}
+	}
+	if len(fieldErrors) > 0 {
+		return domain.TransactionDetail{}, fieldErrors
+	}
+
+	var transactionDetail domain.TransactionDetail
+	transactionDetail.BankDest, _ = value("bank_dest")
+	if bank, ok := opts.Banks.Lookup(transactionDetail.BankDest); ok {
+		transactionDetail.BankDest = bank.Code
+	}
+	transactionDetail.AccountIDDest, _ = value("account_id_dest")
+	transactionDetail.AccountNameDest, _ = value("account_name_dest")
+
+	rowCurrency, _ := value("currency")
+	transactionDetail.Currency = domain.NormalizeCurrency(rowCurrency, opts.Currency)
+
+	if description, _ := value("description"); description != "" {
+		transactionDetail.Description = null.StringFrom(description)
+	}
+
+	if transferDate, _ := value("transfer_date"); transferDate != "" {
+		parsed, err := parseTransferDate(transferDate)
+		if err != nil {
+			fieldErrors = append(fieldErrors, domain.FieldError{
+				Field:   "transfer_date",
+				Message: fmt.Sprintf("transfer_date %q must be formatted as YYYY-MM-DD", transferDate),
+			})
+		} else if opts.ValidateTransferDate != nil {
+			if err := opts.ValidateTransferDate(parsed); err != nil {
+				fieldErrors = append(fieldErrors, domain.FieldError{
+					Field:   "transfer_date",
+					Message: err.Error(),
+				})
+			}
+		}
+		transactionDetail.TransferDate = parsed
+	}
+
+	amountValid := true
+	rawAmount, _ := value("amount")
+	amount, err := domain.ParseMoney(rawAmount, domain.MoneyMaxDecimals)
+	if err != nil {
+		amountValid = false
+		fieldErrors = append(fieldErrors, domain.FieldError{
+			Field:   "amount",
+			Message: fmt.Sprintf("amount %q: %s", rawAmount, err.Error()),
+		})
+	}
+	transactionDetail.Amount = amount
+
+	for _, fieldError := range domain.ValidateTransactionDetail(transactionDetail, opts.Banks) {
+		if fieldError.Field == "amount" && !amountValid {
+			continue
+		}
+		fieldErrors = append(fieldErrors, fieldError)
+	}
+
+	return transactionDetail, fieldErrors
+}
+
+func parseTransferDate(value string) (time.Time, error) {
+	var err error
+	for _, layout := range transferDateLayouts {
+		var parsed time.Time
+		parsed, err = time.Parse(layout, value)
+		if err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, err
+}
@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"batch-transaction/internal/domain"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+type XLSXParser struct {
+	opts Options
+}
+
+func NewXLSXParser(opts Options) *XLSXParser {
+	return &XLSXParser{
+		opts: opts,
+	}
+}
+
+// Parse reads the first sheet of the workbook. The first row is the header and
+// blank rows are skipped, the same way the CSV parser maps columns.
+func (p *XLSXParser) Parse(file io.Reader) ([]domain.TransactionDetail, error) {
+	workbook, err := excelize.OpenReader(file)
+	if err != nil {
+		return nil, invalidFile([]domain.FieldError{{Field: "file", Message: "file is not a valid XLSX workbook"}})
+	}
+	defer workbook.Close()
+
+	sheets := workbook.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, invalidFile([]domain.FieldError{{Field: "file", Message: "file is empty"}})
+	}
+
+	// raw values keep amounts free of display formatting such as thousand
+	// separators
+	sheetRows, err := workbook.GetRows(sheets[0], excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, err
+	}
+	if len(sheetRows) == 0 {
+		return nil, invalidFile([]domain.FieldError{{Field: "file", Message: "file is empty"}})
+	}
+
+	columns, fieldErrors := mapColumns(sheetRows[0], p.opts.ColumnAliases)
+	if len(fieldErrors) > 0 {
+		return nil, invalidFile(fieldErrors)
+	}
+
+	var rows []row
+	for i, values := range sheetRows[1:] {
+		if isBlankRow(values) {
+			continue
+		}
+		convertExcelDate(values, columns)
+		rows = append(rows, row{line: i + 2, values: values})
+	}
+
+	return parseRows(rows, columns, p.opts)
+}
+
+func isBlankRow(values []string) bool {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// convertExcelDate rewrites a date cell stored as an Excel serial number into
+// the YYYY-MM-DD form the row parser expects.
+func convertExcelDate(values []string, columns map[string]int) {
+	i, ok := columns["transfer_date"]
+	if !ok || i >= len(values) {
+		return
+	}
+
+	serial, err := strconv.ParseFloat(strings.TrimSpace(values[i]), 64)
+	if err != nil {
+		return
+	}
+
+	date, err := excelize.ExcelDateToTime(serial, false)
+	if err != nil {
+		return
+	}
+	values[i] = date.Format("2006-01-02")
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"batch-transaction/internal/database"
+	"batch-transaction/internal/domain"
+	"context"
+)
+
+type BankRepository struct {
+	DB *database.DB
+}
+
+func NewBankRepository(db *database.DB) *BankRepository {
+	return &BankRepository{
+		DB: db,
+	}
+}
+
+func (r *BankRepository) GetBanks(ctx context.Context) ([]domain.Bank, error) {
+	var result []domain.Bank
+
+	query := `SELECT code, name, swift_code, account_pattern, active, created_at, updated_at
+	FROM banks ORDER BY code`
+
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bank domain.Bank
+		err = rows.Scan(&bank.Code,
+			&bank.Name,
+			&bank.SwiftCode,
+			&bank.AccountPattern,
+			&bank.Active,
+			&bank.CreatedAt,
+			&bank.UpdatedAt)
+		if err != nil {
+			return result, err
+		}
+		result = append(result, bank)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *BankRepository) CreateBank(ctx context.Context, bank domain.Bank) error {
+	result, err := r.DB.ExecContext(ctx, `INSERT INTO banks (code, name, swift_code, account_pattern, active)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (code) DO NOTHING`,
+		bank.Code, bank.Name, bank.SwiftCode, bank.AccountPattern, bank.Active)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrBankExists
+	}
+
+	return nil
+}
+
+func (r *BankRepository) UpdateBank(ctx context.Context, bank domain.Bank) error {
+	result, err := r.DB.ExecContext(ctx, `UPDATE banks
+		SET name = $2, swift_code = $3, account_pattern = $4, active = $5, updated_at = NOW()
+		WHERE code = $1`,
+		bank.Code, bank.Name, bank.SwiftCode, bank.AccountPattern, bank.Active)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrBankNotFound
+	}
+
+	return nil
+}
+
+func (r *BankRepository) SeedBanks(ctx context.Context, banks []domain.Bank) error {
+	tx, err := r.DB.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, bank := range banks {
+		_, err = tx.ExecContext(ctx, `INSERT INTO banks (code, name, swift_code, account_pattern, active)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (code) DO NOTHING`,
+			bank.Code, bank.Name, bank.SwiftCode, bank.AccountPattern, bank.Active)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"batch-transaction/internal/database"
+	"batch-transaction/internal/domain"
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type BeneficiaryRepository struct {
+	DB *database.DB
+}
+
+func NewBeneficiaryRepository(db *database.DB) *BeneficiaryRepository {
+	return &BeneficiaryRepository{
+		DB: db,
+	}
+}
+
+func (r *BeneficiaryRepository) CreateBeneficiary(ctx context.Context, beneficiary domain.Beneficiary) error {
+	result, err := r.DB.ExecContext(ctx, `INSERT INTO beneficiaries (id, account_number, bank_dest, account_id_dest, account_name_dest, currency, description, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (account_number, bank_dest, account_id_dest) DO NOTHING`,
+		beneficiary.ID, beneficiary.AccountNumber, beneficiary.BankDest, beneficiary.AccountIDDest, beneficiary.AccountNameDest, beneficiary.Currency, beneficiary.Description, beneficiary.CreatedBy)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrBeneficiaryExists
+	}
+
+	return nil
+}
+
+func (r *BeneficiaryRepository) GetBeneficiaries(ctx context.Context, accountNumber string) ([]domain.Beneficiary, error) {
+	query := `SELECT id, account_number, bank_dest, account_id_dest, account_name_dest, currency, description, created_by, created_at
+	FROM beneficiaries WHERE account_number = $1 ORDER BY account_name_dest`
+
+	rows, err := r.DB.QueryContext(ctx, query, accountNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanBeneficiaries(rows)
+}
+
+func (r *BeneficiaryRepository) GetBeneficiariesByID(ctx context.Context, accountNumber string, ids []uuid.UUID) ([]domain.Beneficiary, error) {
+	query := `SELECT id, account_number, bank_dest, account_id_dest, account_name_dest, currency, description, created_by, created_at
+	FROM beneficiaries WHERE account_number = $1 AND id = ANY($2::uuid[])`
+
+	rows, err := r.DB.QueryContext(ctx, query, accountNumber, pq.Array(uuidStrings(ids)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanBeneficiaries(rows)
+}
+
+func scanBeneficiaries(rows *sql.Rows) ([]domain.Beneficiary, error) {
+	var result []domain.Beneficiary
+	for rows.Next() {
+		var beneficiary domain.Beneficiary
+		err := rows.Scan(&beneficiary.ID,
+			&beneficiary.AccountNumber,
+			&beneficiary.BankDest,
+			&beneficiary.AccountIDDest,
+			&beneficiary.AccountNameDest,
+			&beneficiary.Currency,
+			&beneficiary.Description,
+			&beneficiary.CreatedBy,
+			&beneficiary.CreatedAt)
+		if err != nil {
+			return result, err
+		}
+		result = append(result, beneficiary)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *BeneficiaryRepository) CreateTemplate(ctx context.Context, template domain.TransferTemplate) error {
+	tx, err := r.DB.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `INSERT INTO transfer_templates (id, account_number, name, from_account, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (account_number, name) DO NOTHING`,
+		template.ID, template.AccountNumber, template.Name, template.FromAccount, template.CreatedBy)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrTemplateExists
+	}
+
+	for i, item := range template.Items {
+		_, err = tx.ExecContext(ctx, `INSERT INTO transfer_template_items (template_id, beneficiary_id, position, amount, description)
+			VALUES ($1, $2, $3, $4, $5)`,
+			template.ID, item.Beneficiary.ID, i+1, item.Amount, item.Description)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *BeneficiaryRepository) GetTemplates(ctx context.Context, accountNumber string) ([]domain.TransferTemplate, error) {
+	var result []domain.TransferTemplate
+
+	query := `SELECT id, account_number, name, from_account, created_by, created_at
+	FROM transfer_templates WHERE account_number = $1 ORDER BY name`
+
+	rows, err := r.DB.QueryContext(ctx, query, accountNumber)
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var template domain.TransferTemplate
+		err = rows.Scan(&template.ID,
+			&template.AccountNumber,
+			&template.Name,
+			&template.FromAccount,
+			&template.CreatedBy,
+			&template.CreatedAt)
+		if err != nil {
+			return result, err
+		}
+		result = append(result, template)
+	}
+	if err = rows.Err(); err != nil {
+		return result, err
+	}
+
+	if len(result) == 0 {
+		return result, nil
+	}
+
+	ids := make([]uuid.UUID, len(result))
+	for i, template := range result {
+		ids[i] = template.ID
+	}
+	items, err := r.getTemplateItems(ctx, ids)
+	if err != nil {
+		return result, err
+	}
+	for i := range result {
+		result[i].Items = items[result[i].ID]
+	}
+
+	return result, nil
+}
+
+func (r *BeneficiaryRepository) GetTemplateByID(ctx context.Context, accountNumber string, id uuid.UUID) (domain.TransferTemplate, error) {
+	var template domain.TransferTemplate
+
+	query := `SELECT id, account_number, name, from_account, created_by, created_at
+	FROM transfer_templates WHERE id = $1 AND account_number = $2`
+
+	err := r.DB.QueryRowContext(ctx, query, id, accountNumber).Scan(&template.ID,
+		&template.AccountNumber,
+		&template.Name,
+		&template.FromAccount,
+		&template.CreatedBy,
+		&template.CreatedAt)
+	if err == sql.ErrNoRows {
+		return template, domain.ErrTemplateNotFound
+	}
+	if err != nil {
+		return template, err
+	}
+
+	items, err := r.getTemplateItems(ctx, []uuid.UUID{template.ID})
+	if err != nil {
+		return template, err
+	}
+	template.Items = items[template.ID]
+
+	return template, nil
+}
+
+// getTemplateItems returns the items of each template in their saved order.
+func (r *BeneficiaryRepository) getTemplateItems(ctx context.Context, templateIDs []uuid.UUID) (map[uuid.UUID][]domain.TemplateItem, error) {
+	result := map[uuid.UUID][]domain.TemplateItem{}
+
+	query := `SELECT i.template_id, i.amount, i.description,
+		b.id, b.account_number, b.bank_dest, b.account_id_dest, b.account_name_dest, b.currency, b.description, b.created_by, b.created_at
+	FROM transfer_template_items i
+	JOIN beneficiaries b ON b.id = i.beneficiary_id
+	WHERE i.template_id = ANY($1::uuid[])
+	ORDER BY i.template_id, i.position`
+
+	rows, err := r.DB.QueryContext(ctx, query, pq.Array(uuidStrings(templateIDs)))
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var templateID uuid.UUID
+		var item domain.TemplateItem
+		err = rows.Scan(&templateID,
+			&item.Amount,
+			&item.Description,
+			&item.Beneficiary.ID,
+			&item.Beneficiary.AccountNumber,
+			&item.Beneficiary.BankDest,
+			&item.Beneficiary.AccountIDDest,
+			&item.Beneficiary.AccountNameDest,
+			&item.Beneficiary.Currency,
+			&item.Beneficiary.Description,
+			&item.Beneficiary.CreatedBy,
+			&item.Beneficiary.CreatedAt)
+		if err != nil {
+			return result, err
+		}
+		result[templateID] = append(result[templateID], item)
+	}
+
+	return result, rows.Err()
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	result := make([]string, len(ids))
+	for i, id := range ids {
+		result[i] = id.String()
+	}
+	return result
+}
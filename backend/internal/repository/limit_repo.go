@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"batch-transaction/internal/database"
+	"batch-transaction/internal/domain"
+	"context"
+	"database/sql"
+	"time"
+)
+
+type LimitRepository struct {
+	DB *database.DB
+}
+
+func NewLimitRepository(db *database.DB) *LimitRepository {
+	return &LimitRepository{
+		DB: db,
+	}
+}
+
+// queryer is implemented by both *database.DB and *sql.Tx, so limits can be
+// read on their own or inside the transaction approving a batch.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func (r *LimitRepository) GetTransferLimits(ctx context.Context, accountNumber string, userID string) ([]domain.TransferLimit, error) {
+	return getTransferLimits(ctx, r.DB, accountNumber, userID)
+}
+
+func (r *LimitRepository) ListTransferLimits(ctx context.Context, accountNumber string) ([]domain.TransferLimit, error) {
+	var result []domain.TransferLimit
+
+	query := `SELECT account_number, user_id, currency, max_per_row, max_per_batch, daily_limit
+	FROM transfer_limits WHERE account_number = $1
+	ORDER BY user_id, currency`
+
+	rows, err := r.DB.QueryContext(ctx, query, accountNumber)
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	return scanTransferLimits(rows)
+}
+
+func (r *LimitRepository) GetLimitUsage(ctx context.Context, accountNumber string, maker string, since time.Time) (domain.LimitUsage, error) {
+	return getLimitUsage(ctx, r.DB, accountNumber, maker, since)
+}
+
+func (r *LimitRepository) SaveTransferLimit(ctx context.Context, limit domain.TransferLimit) error {
+	_, err := r.DB.ExecContext(ctx, `INSERT INTO transfer_limits (account_number, user_id, currency, max_per_row, max_per_batch, daily_limit)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (account_number, user_id, currency) DO UPDATE
+		SET max_per_row = EXCLUDED.max_per_row, max_per_batch = EXCLUDED.max_per_batch, daily_limit = EXCLUDED.daily_limit, updated_at = NOW()`,
+		limit.AccountNumber, limit.UserID, limit.Currency, limit.MaxPerRow, limit.MaxPerBatch, limit.DailyLimit)
+	return err
+}
+
+func getTransferLimits(ctx context.Context, q queryer, accountNumber string, userID string) ([]domain.TransferLimit, error) {
+	var result []domain.TransferLimit
+
+	query := `SELECT account_number, user_id, currency, max_per_row, max_per_batch, daily_limit
+	FROM transfer_limits
+	WHERE account_number = $1 AND (user_id = '' OR user_id = $2)
+	ORDER BY user_id, currency`
+
+	rows, err := q.QueryContext(ctx, query, accountNumber, userID)
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	return scanTransferLimits(rows)
+}
+
+func scanTransferLimits(rows *sql.Rows) ([]domain.TransferLimit, error) {
+	var result []domain.TransferLimit
+	for rows.Next() {
+		var limit domain.TransferLimit
+		err := rows.Scan(&limit.AccountNumber,
+			&limit.UserID,
+			&limit.Currency,
+			&limit.MaxPerRow,
+			&limit.MaxPerBatch,
+			&limit.DailyLimit)
+		if err != nil {
+			return result, err
+		}
+		result = append(result, limit)
+	}
+
+	return result, rows.Err()
+}
+
+func getLimitUsage(ctx context.Context, q queryer, accountNumber string, maker string, since time.Time) (domain.LimitUsage, error) {
+	usage := domain.LimitUsage{
+		Corporate: map[string]domain.Money{},
+		User:      map[string]domain.Money{},
+	}
+
+	query := `SELECT d.currency, t.maker = $3 AS own, SUM(d.amount)
+	FROM transaction_details d
+	JOIN transactions t ON t.id = d.transaction_id
+	WHERE t.account_number = $1 AND t.approved_at >= $2
+	GROUP BY d.currency, own`
+
+	rows, err := q.QueryContext(ctx, query, accountNumber, since, maker)
+	if err != nil {
+		return usage, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var currency string
+		var own bool
+		var amount domain.Money
+		err = rows.Scan(&currency, &own, &amount)
+		if err != nil {
+			return usage, err
+		}
+		usage.Corporate[currency] += amount
+		if own {
+			usage.User[currency] += amount
+		}
+	}
+
+	return usage, rows.Err()
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"batch-transaction/internal/database"
+	"batch-transaction/internal/domain"
+	"context"
+	"database/sql"
+)
+
+type LinkedAccountRepository struct {
+	DB *database.DB
+}
+
+func NewLinkedAccountRepository(db *database.DB) *LinkedAccountRepository {
+	return &LinkedAccountRepository{
+		DB: db,
+	}
+}
+
+func (r *LinkedAccountRepository) CreateLinkedAccount(ctx context.Context, linkedAccount domain.LinkedAccount) error {
+	result, err := r.DB.ExecContext(ctx, `INSERT INTO linked_accounts (account_number, linked_account, created_by) 
+		VALUES ($1, $2, $3)
+		ON CONFLICT (account_number, linked_account) DO NOTHING`,
+		linkedAccount.AccountNumber, linkedAccount.LinkedAccount, linkedAccount.CreatedBy)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrLinkedAccountExists
+	}
+
+	return nil
+}
+
+func (r *LinkedAccountRepository) GetLinkedAccounts(ctx context.Context, accountNumber string) ([]domain.LinkedAccount, error) {
+	var result []domain.LinkedAccount
+
+	query := `SELECT id, account_number, linked_account, created_by, created_at
+	FROM linked_accounts WHERE account_number = $1 ORDER BY linked_account`
+
+	rows, err := r.DB.QueryContext(ctx, query, accountNumber)
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var linkedAccount domain.LinkedAccount
+		err = rows.Scan(&linkedAccount.ID,
+			&linkedAccount.AccountNumber,
+			&linkedAccount.LinkedAccount,
+			&linkedAccount.CreatedBy,
+			&linkedAccount.CreatedAt)
+		if err != nil {
+			return result, err
+		}
+		result = append(result, linkedAccount)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *LinkedAccountRepository) GetAccountOwner(ctx context.Context, account string) (string, error) {
+	var owner string
+	err := r.DB.QueryRowContext(ctx, `SELECT owner FROM corporate_accounts WHERE account_number = $1`, account).
+		Scan(&owner)
+	if err == sql.ErrNoRows {
+		return "", domain.ErrAccountNotOwned
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return owner, nil
+}
+
+func (r *LinkedAccountRepository) SaveCorporateAccount(ctx context.Context, account domain.CorporateAccount) error {
+	_, err := r.DB.ExecContext(ctx, `INSERT INTO corporate_accounts (account_number, owner) VALUES ($1, $2)
+		ON CONFLICT (account_number) DO UPDATE SET owner = EXCLUDED.owner`,
+		account.AccountNumber, account.Owner)
+	return err
+}
+
+func (r *LinkedAccountRepository) IsLinkedAccount(ctx context.Context, accountNumber string, linkedAccount string) (bool, error) {
+	var id int
+	err := r.DB.QueryRowContext(ctx,
+		`SELECT id FROM linked_accounts WHERE account_number = $1 AND linked_account = $2`, accountNumber, linkedAccount).
+		Scan(&id)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -5,9 +5,13 @@ import (
 	"batch-transaction/internal/domain"
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
+	null "gopkg.in/guregu/null.v4"
 )
 
 type TransactionRepository struct {
@@ -20,15 +24,16 @@ func NewTransactionRepository(db *database.DB) *TransactionRepository {
 	}
 }
 
-func (r *TransactionRepository) GetTransactionSummary(ctx context.Context) (domain.TransactionSummaryResult, error) {
+func (r *TransactionRepository) GetTransactionSummary(ctx context.Context, accountNumber string) (domain.TransactionSummaryResult, error) {
 	var result domain.TransactionSummaryResult
 	query := `SELECT 
 				COALESCE(SUM(CASE WHEN transaction_status = 'waiting_approval' THEN 1 ELSE 0 END),0) as total_waiting_approval,
 				COALESCE(SUM(CASE WHEN transaction_status = 'approved' THEN 1 ELSE 0 END),0) as total_approved,
 				COALESCE(SUM(CASE WHEN transaction_status = 'rejected' THEN 1 ELSE 0 END),0) as total_rejected
 			FROM transactions
+			WHERE account_number = $1
 	`
-	row := r.DB.QueryRowContext(ctx, query)
+	row := r.DB.QueryRowContext(ctx, query, accountNumber)
 	if row.Err() != nil {
 		return result, row.Err()
 	}
@@ -38,44 +43,373 @@ func (r *TransactionRepository) GetTransactionSummary(ctx context.Context) (doma
 		return result, err
 	}
 
-	return result, nil
+	currencyQuery := `SELECT d.currency,
+				COALESCE(SUM(CASE WHEN t.transaction_status = 'waiting_approval' THEN d.amount ELSE 0 END),0) as total_waiting_approval,
+				COALESCE(SUM(CASE WHEN t.transaction_status = 'approved' THEN d.amount ELSE 0 END),0) as total_approved,
+				COALESCE(SUM(CASE WHEN t.transaction_status = 'rejected' THEN d.amount ELSE 0 END),0) as total_rejected
+			FROM transaction_details d
+			JOIN transactions t ON t.id = d.transaction_id
+			WHERE t.account_number = $1
+			GROUP BY d.currency
+			ORDER BY d.currency
+	`
+	rows, err := r.DB.QueryContext(ctx, currencyQuery, accountNumber)
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var summary domain.CurrencySummary
+		err = rows.Scan(&summary.Currency, &summary.TotalWaitingApproval, &summary.TotalApproved, &summary.TotalRejected)
+		if err != nil {
+			return result, err
+		}
+		result.CurrencyTotals = append(result.CurrencyTotals, summary)
+	}
+
+	return result, rows.Err()
 }
 
-func (r *TransactionRepository) UpdateTransaction(ctx context.Context, trx domain.Transaction) error {
-	_, err := r.DB.ExecContext(ctx, `UPDATE transactions SET transaction_status = $1 WHERE id = $2`, trx.TransactionStatus, trx.ID)
+func (r *TransactionRepository) UpdateTransactionStatus(ctx context.Context, accountNumber string, event domain.TransactionEvent) error {
+	tx, err := r.DB.BeginTx(ctx)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	return nil
+	result, err := tx.ExecContext(ctx, `UPDATE transactions SET transaction_status = $1 WHERE id = $2 AND account_number = $3 AND transaction_status = $4`, event.NewStatus, event.TransactionID, accountNumber, event.PreviousStatus)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		// nothing was updated, tell a missing transaction apart from one that
+		// moved to another status in the meantime
+		_, err = r.GetTransactionByID(ctx, accountNumber, event.TransactionID)
+		if err != nil {
+			return err
+		}
+		return domain.ErrInvalidTransition
+	}
+
+	err = insertTransactionEvent(ctx, tx, event)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func insertTransactionEvent(ctx context.Context, tx *sql.Tx, event domain.TransactionEvent) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO transaction_events (transaction_id, actor, actor_role, previous_status, new_status, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, event.TransactionID, event.Actor, event.ActorRole, event.PreviousStatus, event.NewStatus, event.Reason, event.CreatedAt)
+	return err
+}
+
+func (r *TransactionRepository) GetTransactionEvents(ctx context.Context, accountNumber string, transactionID uuid.UUID) ([]domain.TransactionEvent, error) {
+	var result []domain.TransactionEvent
+
+	query := `SELECT e.id, e.transaction_id, e.actor, e.actor_role, e.previous_status, e.new_status, e.reason, e.created_at
+	FROM transaction_events e
+	JOIN transactions t ON t.id = e.transaction_id
+	WHERE e.transaction_id = $1 AND t.account_number = $2
+	ORDER BY e.created_at, e.id`
+
+	rows, err := r.DB.QueryContext(ctx, query, transactionID, accountNumber)
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var event domain.TransactionEvent
+		err = rows.Scan(&event.ID,
+			&event.TransactionID,
+			&event.Actor,
+			&event.ActorRole,
+			&event.PreviousStatus,
+			&event.NewStatus,
+			&event.Reason,
+			&event.CreatedAt)
+		if err != nil {
+			return result, err
+		}
+		result = append(result, event)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *TransactionRepository) GetTransactionByID(ctx context.Context, accountNumber string, transactionID uuid.UUID) (domain.Transaction, error) {
+	var transaction domain.Transaction
+
+	query := `SELECT id, account_number, total_amount, total_record, currency, mixed_currency, from_account, maker, transfer_date, transaction_status, required_approvals, parent_transaction_id, created_at
+	FROM transactions WHERE id = $1 AND account_number = $2`
+
+	err := r.DB.QueryRowContext(ctx, query, transactionID, accountNumber).Scan(&transaction.ID,
+		&transaction.AccountNumber,
+		&transaction.TotalAmount,
+		&transaction.TotalRecord,
+		&transaction.Currency,
+		&transaction.MixedCurrency,
+		&transaction.FromAccount,
+		&transaction.Maker,
+		&transaction.TransferDate,
+		&transaction.TransactionStatus,
+		&transaction.RequiredApprovals,
+		&transaction.ParentTransactionID,
+		&transaction.CreatedAt)
+	if err == sql.ErrNoRows {
+		return transaction, domain.ErrTransactionNotFound
+	}
+	if err != nil {
+		return transaction, err
+	}
+
+	currencyTotals, err := r.getCurrencyTotals(ctx, []uuid.UUID{transaction.ID})
+	if err != nil {
+		return transaction, err
+	}
+	transaction.CurrencyTotals = currencyTotals[transaction.ID]
+
+	return transaction, nil
+}
+
+// getCurrencyTotals sums the rows of each transaction by currency.
+func (r *TransactionRepository) getCurrencyTotals(ctx context.Context, transactionIDs []uuid.UUID) (map[uuid.UUID][]domain.CurrencyTotal, error) {
+	result := map[uuid.UUID][]domain.CurrencyTotal{}
+
+	query := `SELECT transaction_id, currency, SUM(amount), COUNT(*)
+	FROM transaction_details
+	WHERE transaction_id = ANY($1::uuid[])
+	GROUP BY transaction_id, currency
+	ORDER BY transaction_id, currency`
+
+	rows, err := r.DB.QueryContext(ctx, query, pq.Array(uuidStrings(transactionIDs)))
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var transactionID uuid.UUID
+		var total domain.CurrencyTotal
+		err = rows.Scan(&transactionID, &total.Currency, &total.TotalAmount, &total.TotalRecord)
+		if err != nil {
+			return result, err
+		}
+		result[transactionID] = append(result[transactionID], total)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *TransactionRepository) ApproveTransaction(ctx context.Context, accountNumber string, approval domain.TransactionApproval, event domain.TransactionEvent) (domain.ApprovalProgress, error) {
+	var progress domain.ApprovalProgress
+
+	tx, err := r.DB.BeginTx(ctx)
+	if err != nil {
+		return progress, err
+	}
+	defer tx.Rollback()
+
+	// lock the transaction so concurrent approvals are counted one at a time
+	var status domain.TransactionStatus
+	var maker string
+	err = tx.QueryRowContext(ctx, `SELECT transaction_status, required_approvals, maker FROM transactions WHERE id = $1 AND account_number = $2 FOR UPDATE`, approval.TransactionID, accountNumber).
+		Scan(&status, &progress.RequiredApprovals, &maker)
+	if err == sql.ErrNoRows {
+		return progress, domain.ErrTransactionNotFound
+	}
+	if err != nil {
+		return progress, err
+	}
+	if status != domain.WaitingApproval {
+		return progress, domain.ErrInvalidTransition
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO transaction_approvals (transaction_id, approver, approved_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (transaction_id, approver) DO NOTHING
+	`, approval.TransactionID, approval.Approver, approval.ApprovedAt)
+	if err != nil {
+		return progress, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return progress, err
+	}
+	if affected == 0 {
+		return progress, domain.ErrAlreadyApproved
+	}
+
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM transaction_approvals WHERE transaction_id = $1`, approval.TransactionID).
+		Scan(&progress.Approvals)
+	if err != nil {
+		return progress, err
+	}
+
+	if progress.Approvals >= progress.RequiredApprovals {
+		err = checkTransferLimits(ctx, tx, accountNumber, maker, approval)
+		if err != nil {
+			return progress, err
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE transactions SET transaction_status = $1, approved_at = $2 WHERE id = $3`, domain.Approved, approval.ApprovedAt, approval.TransactionID)
+		if err != nil {
+			return progress, err
+		}
+		progress.Approved = true
+	}
+
+	// the event records whether this approval finalized the transaction
+	event.NewStatus = domain.WaitingApproval
+	if progress.Approved {
+		event.NewStatus = domain.Approved
+	}
+	err = insertTransactionEvent(ctx, tx, event)
+	if err != nil {
+		return progress, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return progress, err
+	}
+
+	return progress, nil
+}
+
+// checkTransferLimits checks the batch being approved against the limits of
+// the corporate and its maker. Approvals of the same corporate are serialized
+// on an advisory lock held until tx ends, so the daily usage read here cannot
+// change before this approval is committed.
+func checkTransferLimits(ctx context.Context, tx *sql.Tx, accountNumber string, maker string, approval domain.TransactionApproval) error {
+	_, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('transfer_limits:' || $1))`, accountNumber)
+	if err != nil {
+		return err
+	}
+
+	limits, err := getTransferLimits(ctx, tx, accountNumber, maker)
+	if err != nil {
+		return err
+	}
+	if len(limits) == 0 {
+		return nil
+	}
+
+	usage, err := getLimitUsage(ctx, tx, accountNumber, maker, approval.ApprovedAt.Add(-domain.LimitWindow))
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT amount, currency FROM transaction_details WHERE transaction_id = $1`, approval.TransactionID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var details []domain.TransactionDetail
+	for rows.Next() {
+		var detail domain.TransactionDetail
+		err = rows.Scan(&detail.Amount, &detail.Currency)
+		if err != nil {
+			return err
+		}
+		details = append(details, detail)
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	return domain.CheckTransferLimits(limits, details, usage)
+}
+
+func (r *TransactionRepository) GetTransactionApprovals(ctx context.Context, accountNumber string, transactionID uuid.UUID) ([]domain.TransactionApproval, error) {
+	var result []domain.TransactionApproval
+
+	query := `SELECT a.transaction_id, a.approver, a.approved_at
+	FROM transaction_approvals a
+	JOIN transactions t ON t.id = a.transaction_id
+	WHERE a.transaction_id = $1 AND t.account_number = $2
+	ORDER BY a.approved_at`
+
+	rows, err := r.DB.QueryContext(ctx, query, transactionID, accountNumber)
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var approval domain.TransactionApproval
+		err = rows.Scan(&approval.TransactionID, &approval.Approver, &approval.ApprovedAt)
+		if err != nil {
+			return result, err
+		}
+		result = append(result, approval)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *TransactionRepository) GetRequiredApprovals(ctx context.Context, accountNumber string, totals []domain.CurrencyTotal) (int, error) {
+	var required int
+
+	currencies := make([]string, len(totals))
+	amounts := make([]string, len(totals))
+	for i, total := range totals {
+		currencies[i] = total.Currency
+		amounts[i] = total.TotalAmount.String()
+	}
+
+	query := `SELECT COALESCE(MAX(p.required_approvals), 1)
+	FROM approval_policies p
+	JOIN unnest($2::text[], $3::numeric[]) AS t(currency, total_amount)
+		ON p.currency = t.currency AND p.min_amount <= t.total_amount
+	WHERE p.account_number = $1`
+
+	err := r.DB.QueryRowContext(ctx, query, accountNumber, pq.Array(currencies), pq.Array(amounts)).Scan(&required)
+	if err != nil {
+		return 0, err
+	}
+
+	return required, nil
 }
 
 func (r *TransactionRepository) GetTransactionList(ctx context.Context, param domain.TransactionListParam) ([]domain.Transaction, domain.Pagination, error) {
 	var result []domain.Transaction
 	var pagination domain.Pagination
 
-	baseQuery := `SELECT id, total_amount, total_record, from_account, maker, transfer_date, transaction_status, created_at
-    FROM transactions`
-
-	var query string
-	if len(param.StatusFilter) > 0 {
-		query = baseQuery + ` WHERE transaction_status = ANY($1) 
-		ORDER BY created_at DESC 
-		LIMIT $2 
-		OFFSET $3`
-	} else {
-		query = baseQuery + ` ORDER BY created_at DESC
-		LIMIT $1
-		OFFSET $2`
-	}
-
-	var rows *sql.Rows
-	var err error
+	var conditions []string
+	var args []interface{}
+	if !param.AllCorporates {
+		args = append(args, param.AccountNumber)
+		conditions = append(conditions, fmt.Sprintf("account_number = $%d", len(args)))
+	}
 	if len(param.StatusFilter) > 0 {
-		rows, err = r.DB.QueryContext(ctx, query, pq.Array(param.StatusFilter), param.PerPage, (param.Page-1)*param.PerPage)
-	} else {
-		rows, err = r.DB.QueryContext(ctx, query, param.PerPage, (param.Page-1)*param.PerPage)
+		args = append(args, pq.Array(param.StatusFilter))
+		conditions = append(conditions, fmt.Sprintf("transaction_status = ANY($%d)", len(args)))
 	}
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query := fmt.Sprintf(`SELECT id, account_number, total_amount, total_record, currency, mixed_currency, from_account, maker, transfer_date, transaction_status, required_approvals, parent_transaction_id, created_at
+    FROM transactions%s
+		ORDER BY created_at DESC
+		LIMIT $%d
+		OFFSET $%d`, where, len(args)+1, len(args)+2)
+
+	rows, err := r.DB.QueryContext(ctx, query, append(args, param.PerPage, (param.Page-1)*param.PerPage)...)
 	if err != nil {
 		return result, pagination, err
 	}
@@ -84,32 +418,42 @@ func (r *TransactionRepository) GetTransactionList(ctx context.Context, param do
 	for rows.Next() {
 		var transaction domain.Transaction
 		err = rows.Scan(&transaction.ID,
+			&transaction.AccountNumber,
 			&transaction.TotalAmount,
 			&transaction.TotalRecord,
+			&transaction.Currency,
+			&transaction.MixedCurrency,
 			&transaction.FromAccount,
 			&transaction.Maker,
 			&transaction.TransferDate,
 			&transaction.TransactionStatus,
+			&transaction.RequiredApprovals,
+			&transaction.ParentTransactionID,
 			&transaction.CreatedAt)
 		if err != nil {
 			return result, pagination, err
 		}
 		result = append(result, transaction)
 	}
-
-	var countQuery string
-	if len(param.StatusFilter) > 0 {
-		countQuery = `SELECT COUNT(*) FROM transactions WHERE transaction_status = ANY($1)`
-	} else {
-		countQuery = `SELECT COUNT(*) FROM transactions`
+	if err = rows.Err(); err != nil {
+		return result, pagination, err
 	}
 
-	var row *sql.Row
-	if len(param.StatusFilter) > 0 {
-		row = r.DB.QueryRowContext(ctx, countQuery, pq.Array(param.StatusFilter))
-	} else {
-		row = r.DB.QueryRowContext(ctx, countQuery)
+	if len(result) > 0 {
+		ids := make([]uuid.UUID, len(result))
+		for i, transaction := range result {
+			ids[i] = transaction.ID
+		}
+		currencyTotals, err := r.getCurrencyTotals(ctx, ids)
+		if err != nil {
+			return result, pagination, err
+		}
+		for i := range result {
+			result[i].CurrencyTotals = currencyTotals[result[i].ID]
+		}
 	}
+
+	row := r.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM transactions`+where, args...)
 	if row.Err() != nil {
 		return result, pagination, row.Err()
 	}
@@ -136,13 +480,29 @@ func (r *TransactionRepository) GetTransactionList(ctx context.Context, param do
 	return result, pagination, nil
 }
 
-func (r *TransactionRepository) GetTransactionDetailByTransactionID(ctx context.Context, transactionID uuid.UUID) ([]domain.TransactionDetail, error) {
+func (r *TransactionRepository) GetTransactionAccountNumber(ctx context.Context, transactionID uuid.UUID, status domain.TransactionStatus) (string, error) {
+	var accountNumber string
+	err := r.DB.QueryRowContext(ctx, `SELECT account_number FROM transactions WHERE id = $1 AND transaction_status = $2`, transactionID, status).
+		Scan(&accountNumber)
+	if err == sql.ErrNoRows {
+		return "", domain.ErrTransactionNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return accountNumber, nil
+}
+
+func (r *TransactionRepository) GetTransactionDetailByTransactionID(ctx context.Context, accountNumber string, transactionID uuid.UUID) ([]domain.TransactionDetail, error) {
 	var result []domain.TransactionDetail
 
-	query := `SELECT id, transaction_id, bank_dest, account_id_dest, account_name_dest, amount, description, transfer_date
-	FROM transaction_details WHERE transaction_id = $1`
+	query := `SELECT d.id, d.transaction_id, d.bank_dest, d.account_id_dest, d.account_name_dest, d.amount, d.currency, d.description, d.transfer_date, d.status, d.failure_reason, d.executed_at, d.inquiry_result, d.inquired_at
+	FROM transaction_details d
+	JOIN transactions t ON t.id = d.transaction_id
+	WHERE d.transaction_id = $1 AND t.account_number = $2`
 
-	rows, err := r.DB.QueryContext(ctx, query, transactionID)
+	rows, err := r.DB.QueryContext(ctx, query, transactionID, accountNumber)
 	if err != nil {
 		return result, err
 	}
@@ -156,8 +516,14 @@ func (r *TransactionRepository) GetTransactionDetailByTransactionID(ctx context.
 			&detail.AccountIDDest,
 			&detail.AccountNameDest,
 			&detail.Amount,
+			&detail.Currency,
 			&detail.Description,
-			&detail.TransferDate)
+			&detail.TransferDate,
+			&detail.Status,
+			&detail.FailureReason,
+			&detail.ExecutedAt,
+			&detail.InquiryResult,
+			&detail.InquiredAt)
 		if err != nil {
 			return result, err
 		}
@@ -167,17 +533,41 @@ func (r *TransactionRepository) GetTransactionDetailByTransactionID(ctx context.
 	return result, nil
 }
 
-func (r *TransactionRepository) CreateTransaction(ctx context.Context, trx domain.Transaction, trxDetails []domain.TransactionDetail) error {
+func (r *TransactionRepository) CreateTransaction(ctx context.Context, trx domain.Transaction, trxDetails []domain.TransactionDetail, hits []domain.ScreeningHit) error {
 	tx, err := r.DB.BeginTx(ctx)
 	if err != nil {
 		return err
 	}
 
-	_, err = tx.ExecContext(ctx, `
-		INSERT INTO transactions (id, total_amount, total_record, from_account, maker, transfer_date, transaction_status) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	// a single active retry per parent transaction, see
+	// idx_transactions_active_retry
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO transactions (id, account_number, total_amount, total_record, currency, mixed_currency, from_account, maker, transfer_date, transaction_status, required_approvals, fingerprint, beneficiary_fingerprint, parent_transaction_id) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		ON CONFLICT (parent_transaction_id) WHERE transaction_status NOT IN ('rejected', 'cancelled', 'expired') DO NOTHING
 	`,
-		trx.ID, trx.TotalAmount, trx.TotalRecord, trx.FromAccount, trx.Maker, trx.TransferDate, trx.TransactionStatus)
+		trx.ID, trx.AccountNumber, trx.TotalAmount, trx.TotalRecord, trx.Currency, trx.MixedCurrency, trx.FromAccount, trx.Maker, trx.TransferDate, trx.TransactionStatus, trx.RequiredApprovals, trx.Fingerprint, trx.BeneficiaryFingerprint, trx.ParentTransactionID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if affected == 0 {
+		tx.Rollback()
+		return domain.ErrRetryExists
+	}
+
+	err = insertTransactionEvent(ctx, tx, domain.TransactionEvent{
+		TransactionID: trx.ID,
+		Actor:         trx.Maker,
+		ActorRole:     domain.Maker,
+		NewStatus:     domain.TransactionStatus(trx.TransactionStatus),
+		CreatedAt:     trx.CreatedAt,
+	})
 	if err != nil {
 		tx.Rollback()
 		return err
@@ -185,10 +575,22 @@ func (r *TransactionRepository) CreateTransaction(ctx context.Context, trx domai
 
 	for _, detail := range trxDetails {
 		_, err = tx.ExecContext(ctx, `
-			INSERT INTO transaction_details (transaction_id, bank_dest, account_id_dest, account_name_dest, amount, description, transfer_date) 
-			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			INSERT INTO transaction_details (id, transaction_id, bank_dest, account_id_dest, account_name_dest, amount, currency, description, transfer_date) 
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`,
+			detail.ID, detail.TransactionID, detail.BankDest, detail.AccountIDDest, detail.AccountNameDest, detail.Amount, detail.Currency, detail.Description, detail.TransferDate)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	for _, hit := range hits {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO screening_hits (transaction_id, transaction_detail_id, watchlist_name, watchlist_account, matched_on, score)
+			VALUES ($1, $2, $3, $4, $5, $6)
 		`,
-			detail.TransactionID, detail.BankDest, detail.AccountIDDest, detail.AccountNameDest, detail.Amount, detail.Description, detail.TransferDate)
+			trx.ID, hit.TransactionDetailID, hit.WatchlistName, hit.WatchlistAccount, hit.MatchedOn, hit.Score)
 		if err != nil {
 			tx.Rollback()
 			return err
@@ -202,3 +604,298 @@ func (r *TransactionRepository) CreateTransaction(ctx context.Context, trx domai
 
 	return nil
 }
+
+// FindRecentDuplicate looks for a batch from the same source account created
+// after since whose rows or beneficiaries match trx. Rejected batches are
+// ignored and exact matches are preferred over beneficiary-only matches.
+func (r *TransactionRepository) FindRecentDuplicate(ctx context.Context, trx domain.Transaction, since time.Time) (*domain.DuplicateTransaction, error) {
+	var result domain.DuplicateTransaction
+
+	query := `SELECT id, fingerprint = $2 AS identical
+	FROM transactions
+	WHERE from_account = $1
+		AND account_number = $5
+		AND created_at >= $4
+		AND transaction_status <> 'rejected'
+		AND (fingerprint = $2 OR beneficiary_fingerprint = $3)
+	ORDER BY identical DESC, created_at DESC
+	LIMIT 1`
+
+	err := r.DB.QueryRowContext(ctx, query, trx.FromAccount, trx.Fingerprint, trx.BeneficiaryFingerprint, since, trx.AccountNumber).
+		Scan(&result.TransactionID, &result.Identical)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (r *TransactionRepository) TransitionScheduledTransactions(ctx context.Context, from domain.TransactionStatus, to domain.TransactionStatus, before time.Time, event domain.TransactionEvent) ([]uuid.UUID, error) {
+	var result []uuid.UUID
+
+	tx, err := r.DB.BeginTx(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `UPDATE transactions SET transaction_status = $1
+	WHERE transaction_status = $2 AND transfer_date < $3
+	RETURNING id`, to, from, before)
+	if err != nil {
+		return result, err
+	}
+	for rows.Next() {
+		var id uuid.UUID
+		err = rows.Scan(&id)
+		if err != nil {
+			rows.Close()
+			return result, err
+		}
+		result = append(result, id)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return result, err
+	}
+
+	for _, id := range result {
+		event.TransactionID = id
+		event.PreviousStatus = &from
+		event.NewStatus = to
+		err = insertTransactionEvent(ctx, tx, event)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *TransactionRepository) GetTransactionsByStatus(ctx context.Context, status domain.TransactionStatus) ([]domain.Transaction, error) {
+	var result []domain.Transaction
+
+	query := `SELECT id, account_number, total_amount, total_record, currency, mixed_currency, from_account, maker, transfer_date, transaction_status, required_approvals, parent_transaction_id, created_at
+	FROM transactions WHERE transaction_status = $1
+	ORDER BY transfer_date, created_at`
+
+	rows, err := r.DB.QueryContext(ctx, query, status)
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var transaction domain.Transaction
+		err = rows.Scan(&transaction.ID,
+			&transaction.AccountNumber,
+			&transaction.TotalAmount,
+			&transaction.TotalRecord,
+			&transaction.Currency,
+			&transaction.MixedCurrency,
+			&transaction.FromAccount,
+			&transaction.Maker,
+			&transaction.TransferDate,
+			&transaction.TransactionStatus,
+			&transaction.RequiredApprovals,
+			&transaction.ParentTransactionID,
+			&transaction.CreatedAt)
+		if err != nil {
+			return result, err
+		}
+		result = append(result, transaction)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *TransactionRepository) UpdateTransactionDetailStatus(ctx context.Context, detail domain.TransactionDetail) error {
+	result, err := r.DB.ExecContext(ctx, `UPDATE transaction_details SET status = $1, failure_reason = $2, executed_at = $3
+	WHERE id = $4 AND status = $5`, detail.Status, detail.FailureReason, detail.ExecutedAt, detail.ID, domain.DetailPending)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrInvalidTransition
+	}
+
+	return nil
+}
+
+func (r *TransactionRepository) FinishTransaction(ctx context.Context, accountNumber string, event domain.TransactionEvent) (domain.TransactionStatus, error) {
+	tx, err := r.DB.BeginTx(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	var status domain.TransactionStatus
+	err = tx.QueryRowContext(ctx, `SELECT transaction_status FROM transactions WHERE id = $1 AND account_number = $2 FOR UPDATE`, event.TransactionID, accountNumber).
+		Scan(&status)
+	if err == sql.ErrNoRows {
+		return "", domain.ErrTransactionNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	if status != domain.Processing {
+		return status, domain.ErrInvalidTransition
+	}
+
+	var pending, success, failed int
+	err = tx.QueryRowContext(ctx, `SELECT
+			COALESCE(SUM(CASE WHEN status = 'pending' THEN 1 ELSE 0 END),0),
+			COALESCE(SUM(CASE WHEN status = 'success' THEN 1 ELSE 0 END),0),
+			COALESCE(SUM(CASE WHEN status = 'failed' THEN 1 ELSE 0 END),0)
+		FROM transaction_details WHERE transaction_id = $1`, event.TransactionID).
+		Scan(&pending, &success, &failed)
+	if err != nil {
+		return "", err
+	}
+	if pending > 0 {
+		return domain.Processing, nil
+	}
+
+	switch {
+	case failed == 0:
+		event.NewStatus = domain.Completed
+	case success == 0:
+		event.NewStatus = domain.Failed
+	default:
+		event.NewStatus = domain.PartiallyCompleted
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE transactions SET transaction_status = $1 WHERE id = $2`, event.NewStatus, event.TransactionID)
+	if err != nil {
+		return "", err
+	}
+
+	event.PreviousStatus = &status
+	if failed > 0 {
+		event.Reason = null.StringFrom(fmt.Sprintf("%d of %d transfers failed", failed, success+failed))
+	}
+	err = insertTransactionEvent(ctx, tx, event)
+	if err != nil {
+		return "", err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return "", err
+	}
+
+	return event.NewStatus, nil
+}
+
+func (r *TransactionRepository) GetRetryTransactionIDs(ctx context.Context, accountNumber string, transactionID uuid.UUID) ([]uuid.UUID, error) {
+	var result []uuid.UUID
+
+	query := `SELECT id FROM transactions
+	WHERE parent_transaction_id = $1 AND account_number = $2
+	ORDER BY created_at`
+
+	rows, err := r.DB.QueryContext(ctx, query, transactionID, accountNumber)
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uuid.UUID
+		err = rows.Scan(&id)
+		if err != nil {
+			return result, err
+		}
+		result = append(result, id)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *TransactionRepository) GetPendingInquiries(ctx context.Context, limit int) ([]domain.TransactionDetail, error) {
+	var result []domain.TransactionDetail
+
+	query := `SELECT d.id, d.transaction_id, d.bank_dest, d.account_id_dest, d.account_name_dest, d.amount, d.currency, d.description, d.transfer_date, d.status, d.inquiry_result
+	FROM transaction_details d
+	JOIN transactions t ON t.id = d.transaction_id
+	WHERE d.inquiry_result = $1 AND t.transaction_status = $2
+	ORDER BY t.created_at, d.id
+	LIMIT $3`
+
+	rows, err := r.DB.QueryContext(ctx, query, domain.InquiryPending, domain.WaitingApproval, limit)
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var detail domain.TransactionDetail
+		err = rows.Scan(&detail.ID,
+			&detail.TransactionID,
+			&detail.BankDest,
+			&detail.AccountIDDest,
+			&detail.AccountNameDest,
+			&detail.Amount,
+			&detail.Currency,
+			&detail.Description,
+			&detail.TransferDate,
+			&detail.Status,
+			&detail.InquiryResult)
+		if err != nil {
+			return result, err
+		}
+		result = append(result, detail)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *TransactionRepository) UpdateInquiryResult(ctx context.Context, detail domain.TransactionDetail) error {
+	_, err := r.DB.ExecContext(ctx, `UPDATE transaction_details SET inquiry_result = $1, inquired_at = $2
+	WHERE id = $3 AND inquiry_result = $4`, detail.InquiryResult, detail.InquiredAt, detail.ID, domain.InquiryPending)
+	return err
+}
+
+func (r *TransactionRepository) GetScreeningHits(ctx context.Context, accountNumber string, transactionID uuid.UUID) ([]domain.ScreeningHit, error) {
+	var result []domain.ScreeningHit
+
+	query := `SELECT h.transaction_detail_id, h.watchlist_name, h.watchlist_account, h.matched_on, h.score
+	FROM screening_hits h
+	JOIN transactions t ON t.id = h.transaction_id
+	WHERE h.transaction_id = $1 AND t.account_number = $2
+	ORDER BY h.id`
+
+	rows, err := r.DB.QueryContext(ctx, query, transactionID, accountNumber)
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var hit domain.ScreeningHit
+		err = rows.Scan(&hit.TransactionDetailID,
+			&hit.WatchlistName,
+			&hit.WatchlistAccount,
+			&hit.MatchedOn,
+			&hit.Score)
+		if err != nil {
+			return result, err
+		}
+		result = append(result, hit)
+	}
+
+	return result, rows.Err()
+}
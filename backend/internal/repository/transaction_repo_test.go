@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"batch-transaction/internal/database"
+	"batch-transaction/internal/domain"
+	"context"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+const (
+	corporateA = "1111111111"
+	corporateB = "2222222222"
+)
+
+var transactionColumns = []string{"id", "account_number", "total_amount", "total_record", "currency", "mixed_currency", "from_account", "maker", "transfer_date", "transaction_status", "required_approvals", "parent_transaction_id", "created_at"}
+
+func newTestTransactionRepository(t *testing.T) (*TransactionRepository, sqlmock.Sqlmock) {
+	dsn := "sqlmock_" + t.Name()
+	mockDB, mock, err := sqlmock.NewWithDSN(dsn)
+	if err != nil {
+		t.Fatalf("open sqlmock: %v", err)
+	}
+	db, err := database.NewDB("sqlmock", dsn, log.New(io.Discard, "", 0))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() {
+		mockDB.Close()
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+	return NewTransactionRepository(db), mock
+}
+
+func TestGetTransactionSummaryIsScopedToCorporate(t *testing.T) {
+	repo, mock := newTestTransactionRepository(t)
+
+	mock.ExpectQuery(`FROM transactions\s+WHERE account_number = \$1`).
+		WithArgs(corporateA).
+		WillReturnRows(sqlmock.NewRows([]string{"total_waiting_approval", "total_approved", "total_rejected"}).AddRow(1, 0, 0))
+	mock.ExpectQuery(`JOIN transactions t ON t\.id = d\.transaction_id\s+WHERE t\.account_number = \$1`).
+		WithArgs(corporateA).
+		WillReturnRows(sqlmock.NewRows([]string{"currency", "total_waiting_approval", "total_approved", "total_rejected"}))
+
+	_, err := repo.GetTransactionSummary(context.Background(), corporateA)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGetTransactionListIsScopedToCorporate(t *testing.T) {
+	repo, mock := newTestTransactionRepository(t)
+
+	mock.ExpectQuery(`FROM transactions WHERE account_number = \$1\s+ORDER BY created_at DESC\s+LIMIT \$2\s+OFFSET \$3`).
+		WithArgs(corporateA, 10, 0).
+		WillReturnRows(sqlmock.NewRows(transactionColumns))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM transactions WHERE account_number = \$1$`).
+		WithArgs(corporateA).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	_, _, err := repo.GetTransactionList(context.Background(), domain.TransactionListParam{
+		AccountNumber: corporateA,
+		Page:          1,
+		PerPage:       10,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGetTransactionListOfAllCorporatesOnlyFiltersStatus(t *testing.T) {
+	repo, mock := newTestTransactionRepository(t)
+
+	mock.ExpectQuery(`FROM transactions WHERE transaction_status = ANY\(\$1\)\s+ORDER BY`).
+		WithArgs(sqlmock.AnyArg(), 10, 0).
+		WillReturnRows(sqlmock.NewRows(transactionColumns))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM transactions WHERE transaction_status = ANY\(\$1\)$`).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	_, _, err := repo.GetTransactionList(context.Background(), domain.TransactionListParam{
+		Page:          1,
+		PerPage:       10,
+		StatusFilter:  []domain.TransactionStatus{domain.ComplianceReview},
+		AllCorporates: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGetTransactionByIDOfOtherCorporateIsNotFound(t *testing.T) {
+	repo, mock := newTestTransactionRepository(t)
+	transactionID := uuid.New()
+
+	mock.ExpectQuery(`FROM transactions WHERE id = \$1 AND account_number = \$2`).
+		WithArgs(transactionID, corporateB).
+		WillReturnRows(sqlmock.NewRows(transactionColumns))
+
+	_, err := repo.GetTransactionByID(context.Background(), corporateB, transactionID)
+	if err != domain.ErrTransactionNotFound {
+		t.Fatalf("got %v, want %v", err, domain.ErrTransactionNotFound)
+	}
+}
+
+func TestGetTransactionDetailsAreScopedToCorporate(t *testing.T) {
+	repo, mock := newTestTransactionRepository(t)
+	transactionID := uuid.New()
+
+	mock.ExpectQuery(`JOIN transactions t ON t\.id = d\.transaction_id\s+WHERE d\.transaction_id = \$1 AND t\.account_number = \$2`).
+		WithArgs(transactionID, corporateA).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := repo.GetTransactionDetailByTransactionID(context.Background(), corporateA, transactionID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGetTransactionEventsAreScopedToCorporate(t *testing.T) {
+	repo, mock := newTestTransactionRepository(t)
+	transactionID := uuid.New()
+
+	mock.ExpectQuery(`JOIN transactions t ON t\.id = e\.transaction_id\s+WHERE e\.transaction_id = \$1 AND t\.account_number = \$2`).
+		WithArgs(transactionID, corporateA).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := repo.GetTransactionEvents(context.Background(), corporateA, transactionID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestApproveTransactionOfOtherCorporateIsNotFound(t *testing.T) {
+	repo, mock := newTestTransactionRepository(t)
+	transactionID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM transactions WHERE id = \$1 AND account_number = \$2 FOR UPDATE`).
+		WithArgs(transactionID, corporateB).
+		WillReturnRows(sqlmock.NewRows([]string{"transaction_status", "required_approvals", "maker"}))
+	mock.ExpectRollback()
+
+	_, err := repo.ApproveTransaction(context.Background(), corporateB, domain.TransactionApproval{
+		TransactionID: transactionID,
+		Approver:      "approver",
+	}, domain.TransactionEvent{TransactionID: transactionID})
+	if err != domain.ErrTransactionNotFound {
+		t.Fatalf("got %v, want %v", err, domain.ErrTransactionNotFound)
+	}
+}
+
+func TestUpdateTransactionStatusOfOtherCorporateIsNotFound(t *testing.T) {
+	repo, mock := newTestTransactionRepository(t)
+	transactionID := uuid.New()
+	previous := domain.WaitingApproval
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE transactions SET transaction_status = \$1 WHERE id = \$2 AND account_number = \$3 AND transaction_status = \$4`).
+		WithArgs(domain.Rejected, transactionID, corporateB, previous).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`FROM transactions WHERE id = \$1 AND account_number = \$2`).
+		WithArgs(transactionID, corporateB).
+		WillReturnRows(sqlmock.NewRows(transactionColumns))
+	mock.ExpectRollback()
+
+	err := repo.UpdateTransactionStatus(context.Background(), corporateB, domain.TransactionEvent{
+		TransactionID:  transactionID,
+		PreviousStatus: &previous,
+		NewStatus:      domain.Rejected,
+	})
+	if err != domain.ErrTransactionNotFound {
+		t.Fatalf("got %v, want %v", err, domain.ErrTransactionNotFound)
+	}
+}
@@ -70,3 +70,25 @@ func (r *UserRepository) UpdateUserLastLogin(ctx context.Context, userID string)
 
 	return nil
 }
+
+func (r *UserRepository) GetUsersByAccountNumber(ctx context.Context, accountNumber string, role domain.Role) ([]domain.User, error) {
+	var result []domain.User
+
+	query := "SELECT id, account_number, account_name, user_id, user_name, role, phone_number, email FROM users WHERE account_number = $1 AND role = $2 ORDER BY user_id"
+	rows, err := r.DB.QueryContext(ctx, query, accountNumber, role)
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user domain.User
+		err = rows.Scan(&user.ID, &user.AccountNumber, &user.AccountName, &user.UserID, &user.UserName, &user.Role, &user.PhoneNumber, &user.Email)
+		if err != nil {
+			return result, err
+		}
+		result = append(result, user)
+	}
+
+	return result, rows.Err()
+}
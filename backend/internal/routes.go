@@ -9,14 +9,14 @@ import (
 	"github.com/go-chi/cors"
 )
 
-func NewRouter(healthHandler *handler.HealthHandler, otpHandler *handler.OTPHandler, userHandler *handler.UserHandler, transactionHandler *handler.TransactionHandler, jwtService auth.JWTService, config config.ConfigInterface) *chi.Mux {
+func NewRouter(healthHandler *handler.HealthHandler, otpHandler *handler.OTPHandler, userHandler *handler.UserHandler, transactionHandler *handler.TransactionHandler, accountHandler *handler.AccountHandler, beneficiaryHandler *handler.BeneficiaryHandler, bankHandler *handler.BankHandler, limitHandler *handler.LimitHandler, jwtService auth.JWTService, config config.ConfigInterface) *chi.Mux {
 	r := chi.NewRouter()
 
 	cors := cors.New(cors.Options{
 		AllowedOrigins:   []string{"*"}, // Allow all origins
 		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "Idempotency-Key"},
+		ExposedHeaders:   []string{"Link", "Idempotent-Replayed"},
 		AllowCredentials: true,
 		MaxAge:           300, // Maximum age to cache preflight request
 	})
@@ -41,8 +41,41 @@ func NewRouter(healthHandler *handler.HealthHandler, otpHandler *handler.OTPHand
 		r.Post("/create", transactionHandler.CreateTransaction)
 		r.Get("/summary", transactionHandler.GetTransactionSummary)
 		r.Patch("/{id}", transactionHandler.UpdateTransaction)
+		r.Post("/{id}/otp", transactionHandler.SendApprovalOTP)
 		r.Get("/", transactionHandler.GetTransactionList)
 		r.Get("/{id}", transactionHandler.GetTransactionDetail)
+		r.Get("/{id}/history", transactionHandler.GetTransactionHistory)
+		r.Post("/{id}/retry-failed", transactionHandler.RetryFailedTransaction)
+	})
+
+	r.Route("/api/admin", func(r chi.Router) {
+		r.Use(auth.BearerAuthMiddleware(jwtService, config.GetSecretKey()))
+		r.Post("/users", userHandler.CreateCorporateUser)
+		r.Post("/linked-accounts", accountHandler.AddLinkedAccount)
+		r.Get("/linked-accounts", accountHandler.GetLinkedAccounts)
+		r.Get("/banks", bankHandler.GetBanks)
+		r.Get("/transfer-limits", limitHandler.GetTransferLimits)
+		r.Put("/transfer-limits", limitHandler.SaveTransferLimit)
+	})
+
+	r.Route("/api/operator", func(r chi.Router) {
+		r.Use(auth.BearerAuthMiddleware(jwtService, config.GetSecretKey()))
+		r.Get("/banks", bankHandler.GetBanks)
+		r.Post("/banks", bankHandler.AddBank)
+		r.Put("/banks/{code}", bankHandler.UpdateBank)
+	})
+
+	r.Route("/api/beneficiaries", func(r chi.Router) {
+		r.Use(auth.BearerAuthMiddleware(jwtService, config.GetSecretKey()))
+		r.Post("/", beneficiaryHandler.AddBeneficiary)
+		r.Get("/", beneficiaryHandler.GetBeneficiaries)
+	})
+
+	r.Route("/api/templates", func(r chi.Router) {
+		r.Use(auth.BearerAuthMiddleware(jwtService, config.GetSecretKey()))
+		r.Post("/", beneficiaryHandler.CreateTemplate)
+		r.Get("/", beneficiaryHandler.GetTemplates)
+		r.Get("/{id}", beneficiaryHandler.GetTemplate)
 	})
 
 	return r
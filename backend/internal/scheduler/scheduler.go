@@ -0,0 +1,81 @@
+package scheduler
+
+import (
+	"batch-transaction/internal/config"
+	"batch-transaction/internal/domain"
+	"context"
+	"log"
+	"time"
+
+	null "gopkg.in/guregu/null.v4"
+)
+
+// actor is recorded as the user behind status changes made by the scheduler.
+const actor = "scheduler"
+
+// Scheduler periodically releases approved batches whose transfer date has
+// arrived for execution, and expires batches that were not approved in time.
+type Scheduler struct {
+	transactionRepo domain.TransactionRepository
+	configInterface config.ConfigInterface
+	log             *log.Logger
+}
+
+func NewScheduler(transactionRepo domain.TransactionRepository, configInterface config.ConfigInterface, log *log.Logger) *Scheduler {
+	return &Scheduler{
+		transactionRepo: transactionRepo,
+		configInterface: configInterface,
+		log:             log,
+	}
+}
+
+// Run checks scheduled batches every SCHEDULER_INTERVAL until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.configInterface.GetSchedulerInterval())
+	defer ticker.Stop()
+
+	for {
+		s.Tick(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick runs a single check at now.
+func (s *Scheduler) Tick(ctx context.Context, now time.Time) {
+	calendar := s.configInterface.GetTransferCalendar()
+
+	// approved batches dated today or earlier are due
+	released, err := s.transactionRepo.TransitionScheduledTransactions(ctx, domain.Approved, domain.Processing, calendar.Today(now).AddDate(0, 0, 1), domain.TransactionEvent{
+		Actor:     actor,
+		ActorRole: domain.System,
+		Reason:    null.StringFrom("transfer date reached"),
+		CreatedAt: now,
+	})
+	if err != nil {
+		s.log.Println("scheduler: release due transactions:", err)
+	} else if len(released) > 0 {
+		s.log.Println("scheduler: released transactions for execution:", released)
+	}
+
+	// batches still waiting for approval, or for compliance review before
+	// that, can no longer be executed once their date is before the earliest
+	// possible transfer date
+	for _, status := range []domain.TransactionStatus{domain.WaitingApproval, domain.ComplianceReview} {
+		expired, err := s.transactionRepo.TransitionScheduledTransactions(ctx, status, domain.Expired, calendar.EarliestTransferDate(now), domain.TransactionEvent{
+			Actor:     actor,
+			ActorRole: domain.System,
+			Reason:    null.StringFrom("transfer date passed before approval"),
+			CreatedAt: now,
+		})
+		if err != nil {
+			s.log.Println("scheduler: expire overdue transactions:", err)
+		} else if len(expired) > 0 {
+			s.log.Println("scheduler: expired transactions:", expired)
+		}
+	}
+}
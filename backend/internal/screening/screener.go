@@ -0,0 +1,137 @@
+package screening
+
+import (
+	"batch-transaction/internal/domain"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// WatchlistScreener matches beneficiaries against watchlist entries by exact
+// account number, or by name when the similarity of the normalized names is
+// at least Threshold.
+type WatchlistScreener struct {
+	Threshold float64
+
+	entries []watchlistEntry
+}
+
+type watchlistEntry struct {
+	domain.WatchlistEntry
+	name       string
+	sortedName string
+	account    string
+}
+
+func NewWatchlistScreener(entries []domain.WatchlistEntry, threshold float64) *WatchlistScreener {
+	screener := &WatchlistScreener{
+		Threshold: threshold,
+	}
+	for _, entry := range entries {
+		screener.entries = append(screener.entries, watchlistEntry{
+			WatchlistEntry: entry,
+			name:           normalizeName(entry.Name),
+			sortedName:     sortTokens(normalizeName(entry.Name)),
+			account:        normalizeAccount(entry.AccountNumber),
+		})
+	}
+	return screener
+}
+
+func (s *WatchlistScreener) Screen(detail domain.TransactionDetail) []domain.ScreeningHit {
+	var hits []domain.ScreeningHit
+
+	name := normalizeName(detail.AccountNameDest)
+	sortedName := sortTokens(name)
+	account := normalizeAccount(detail.AccountIDDest)
+
+	for _, entry := range s.entries {
+		hit := domain.ScreeningHit{
+			TransactionDetailID: detail.ID,
+			WatchlistName:       entry.Name,
+			WatchlistAccount:    entry.AccountNumber,
+		}
+
+		if entry.account != "" && entry.account == account {
+			hit.MatchedOn = domain.MatchedOnAccountNumber
+			hit.Score = 1
+			hits = append(hits, hit)
+			continue
+		}
+
+		if entry.name == "" || name == "" {
+			continue
+		}
+		// word order differs between sources, so the better of the plain and
+		// the word-sorted comparison counts
+		score := similarity(name, entry.name)
+		if sorted := similarity(sortedName, entry.sortedName); sorted > score {
+			score = sorted
+		}
+		if score >= s.Threshold {
+			hit.MatchedOn = domain.MatchedOnName
+			hit.Score = score
+			hits = append(hits, hit)
+		}
+	}
+
+	return hits
+}
+
+// normalizeName upper-cases name and reduces it to words of letters and
+// digits separated by single spaces.
+func normalizeName(name string) string {
+	words := strings.FieldsFunc(strings.ToUpper(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
+
+func sortTokens(name string) string {
+	words := strings.Fields(name)
+	sort.Strings(words)
+	return strings.Join(words, " ")
+}
+
+func normalizeAccount(account string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) || unicode.IsLetter(r) {
+			return unicode.ToUpper(r)
+		}
+		return -1
+	}, account)
+}
+
+// similarity is 1 minus the edit distance of a and b relative to the longer
+// one, so 1 means equal and 0 means nothing in common.
+func similarity(a string, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a []rune, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
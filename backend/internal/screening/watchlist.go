@@ -0,0 +1,85 @@
+package screening
+
+import (
+	"batch-transaction/internal/domain"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LoadWatchlist reads the watchlist at path, a JSON list of entries or a CSV
+// file with a name and an account_number column.
+func LoadWatchlist(path string) ([]domain.WatchlistEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []domain.WatchlistEntry
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.NewDecoder(file).Decode(&entries)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	case ".csv":
+		entries, err = readCSVWatchlist(csv.NewReader(file))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("%s: watchlist must be a .json or .csv file", path)
+	}
+
+	for i, entry := range entries {
+		if strings.TrimSpace(entry.Name) == "" && strings.TrimSpace(entry.AccountNumber) == "" {
+			return nil, fmt.Errorf("%s: entry %d has neither a name nor an account number", path, i+1)
+		}
+	}
+
+	return entries, nil
+}
+
+func readCSVWatchlist(reader *csv.Reader) ([]domain.WatchlistEntry, error) {
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	nameColumn, accountColumn := -1, -1
+	for i, header := range rows[0] {
+		switch strings.ToLower(strings.TrimSpace(header)) {
+		case "name":
+			nameColumn = i
+		case "account_number":
+			accountColumn = i
+		}
+	}
+	if nameColumn < 0 && accountColumn < 0 {
+		return nil, fmt.Errorf("header needs a name or an account_number column")
+	}
+
+	column := func(row []string, i int) string {
+		if i < 0 || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	var entries []domain.WatchlistEntry
+	for _, row := range rows[1:] {
+		entries = append(entries, domain.WatchlistEntry{
+			Name:          column(row, nameColumn),
+			AccountNumber: column(row, accountColumn),
+		})
+	}
+	return entries, nil
+}
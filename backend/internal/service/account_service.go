@@ -0,0 +1,63 @@
+package service
+
+import (
+	"batch-transaction/internal/domain"
+	"context"
+	"strings"
+)
+
+type AccountService struct {
+	linkedAccountRepo domain.LinkedAccountRepository
+}
+
+func NewAccountService(linkedAccountRepo domain.LinkedAccountRepository) *AccountService {
+	return &AccountService{
+		linkedAccountRepo: linkedAccountRepo,
+	}
+}
+
+// AddLinkedAccount registers another source account for the admin's corporate.
+// The bank must have recorded the account as held by the corporate.
+func (s *AccountService) AddLinkedAccount(ctx context.Context, req domain.LinkedAccountRequest) error {
+	if req.Role != domain.Admin {
+		return domain.ErrRoleNotAllowed
+	}
+
+	req.LinkedAccount = strings.TrimSpace(req.LinkedAccount)
+	err := domain.ValidateLinkedAccountRequest(&req)
+	if err != nil {
+		return err
+	}
+
+	owner, err := s.linkedAccountRepo.GetAccountOwner(ctx, req.LinkedAccount)
+	if err != nil {
+		return err
+	}
+	if owner != req.AccountNumber {
+		return domain.ErrAccountNotOwned
+	}
+
+	return s.linkedAccountRepo.CreateLinkedAccount(ctx, domain.LinkedAccount{
+		AccountNumber: req.AccountNumber,
+		LinkedAccount: req.LinkedAccount,
+		CreatedBy:     req.UserID,
+	})
+}
+
+func (s *AccountService) GetLinkedAccounts(ctx context.Context, accountNumber string, role domain.Role) ([]domain.LinkedAccount, error) {
+	if role != domain.Admin {
+		return nil, domain.ErrRoleNotAllowed
+	}
+
+	return s.linkedAccountRepo.GetLinkedAccounts(ctx, accountNumber)
+}
+
+// IsSourceAccountAllowed reports whether a corporate may send money from
+// fromAccount, which must be its own account number or a linked account.
+func (s *AccountService) IsSourceAccountAllowed(ctx context.Context, accountNumber string, fromAccount string) (bool, error) {
+	if fromAccount == accountNumber {
+		return true, nil
+	}
+
+	return s.linkedAccountRepo.IsLinkedAccount(ctx, accountNumber, fromAccount)
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"batch-transaction/internal/domain"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+type BankService struct {
+	bankRepo domain.BankRepository
+}
+
+func NewBankService(bankRepo domain.BankRepository) *BankService {
+	return &BankService{
+		bankRepo: bankRepo,
+	}
+}
+
+// GetDirectory returns the active banks, so changes made by an admin apply to
+// the next upload.
+func (s *BankService) GetDirectory(ctx context.Context) (*domain.BankDirectory, error) {
+	banks, err := s.bankRepo.GetBanks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.NewBankDirectory(banks), nil
+}
+
+func (s *BankService) GetBanks(ctx context.Context, role domain.Role) ([]domain.Bank, error) {
+	if role != domain.Admin && role != domain.Operator {
+		return nil, domain.ErrRoleNotAllowed
+	}
+
+	return s.bankRepo.GetBanks(ctx)
+}
+
+// AddBank adds a bank to the directory shared by every corporate, so only an
+// operator may change it.
+func (s *BankService) AddBank(ctx context.Context, req domain.BankRequest) error {
+	if req.Role != domain.Operator {
+		return domain.ErrRoleNotAllowed
+	}
+
+	bank := bankFromRequest(req)
+	if fieldErrors := domain.ValidateBank(bank); len(fieldErrors) > 0 {
+		return &domain.ValidationError{
+			Message: "Validation failed",
+			Errors:  fieldErrors,
+		}
+	}
+
+	return s.bankRepo.CreateBank(ctx, bank)
+}
+
+// UpdateBank replaces the directory entry of req.Code. Banks are deactivated
+// rather than deleted, since past transfers refer to them.
+func (s *BankService) UpdateBank(ctx context.Context, req domain.BankRequest) error {
+	if req.Role != domain.Operator {
+		return domain.ErrRoleNotAllowed
+	}
+
+	bank := bankFromRequest(req)
+	if fieldErrors := domain.ValidateBank(bank); len(fieldErrors) > 0 {
+		return &domain.ValidationError{
+			Message: "Validation failed",
+			Errors:  fieldErrors,
+		}
+	}
+
+	return s.bankRepo.UpdateBank(ctx, bank)
+}
+
+// SeedBanks adds the banks listed in the JSON file at path that are not in the
+// directory yet. Entries already in the directory keep any admin changes.
+func (s *BankService) SeedBanks(ctx context.Context, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var requests []domain.BankRequest
+	err = json.Unmarshal(content, &requests)
+	if err != nil {
+		return err
+	}
+
+	banks := make([]domain.Bank, len(requests))
+	for i, req := range requests {
+		banks[i] = bankFromRequest(req)
+		if fieldErrors := domain.ValidateBank(banks[i]); len(fieldErrors) > 0 {
+			return fmt.Errorf("%s: bank %d: %s", path, i+1, fieldErrors[0].Message)
+		}
+	}
+
+	return s.bankRepo.SeedBanks(ctx, banks)
+}
+
+func bankFromRequest(req domain.BankRequest) domain.Bank {
+	bank := domain.Bank{
+		Code:           strings.ToUpper(strings.TrimSpace(req.Code)),
+		Name:           strings.TrimSpace(req.Name),
+		SwiftCode:      strings.ToUpper(strings.TrimSpace(req.SwiftCode)),
+		AccountPattern: strings.TrimSpace(req.AccountPattern),
+		Active:         true,
+	}
+	if req.Active != nil {
+		bank.Active = *req.Active
+	}
+	return bank
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"batch-transaction/internal/domain"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	null "gopkg.in/guregu/null.v4"
+)
+
+type BeneficiaryService struct {
+	beneficiaryRepo domain.BeneficiaryRepository
+	accountService  *AccountService
+	bankService     *BankService
+}
+
+func NewBeneficiaryService(beneficiaryRepo domain.BeneficiaryRepository, accountService *AccountService, bankService *BankService) *BeneficiaryService {
+	return &BeneficiaryService{
+		beneficiaryRepo: beneficiaryRepo,
+		accountService:  accountService,
+		bankService:     bankService,
+	}
+}
+
+// canManage reports whether role may change the beneficiaries and templates
+// of its corporate.
+func canManage(role domain.Role) bool {
+	return role == domain.Maker || role == domain.Admin
+}
+
+// AddBeneficiary saves a beneficiary after checking it with the same rules as
+// uploaded rows.
+func (s *BeneficiaryService) AddBeneficiary(ctx context.Context, req domain.BeneficiaryRequest) (uuid.UUID, error) {
+	if !canManage(req.Role) {
+		return uuid.Nil, domain.ErrRoleNotAllowed
+	}
+
+	banks, err := s.bankService.GetDirectory(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	beneficiary := domain.Beneficiary{
+		ID:              uuid.New(),
+		AccountNumber:   req.AccountNumber,
+		BankDest:        strings.TrimSpace(req.BankDest),
+		AccountIDDest:   strings.TrimSpace(req.AccountIDDest),
+		AccountNameDest: strings.TrimSpace(req.AccountNameDest),
+		Currency:        domain.NormalizeCurrency(req.Currency, domain.DefaultCurrency),
+		CreatedBy:       req.UserID,
+	}
+	if bank, ok := banks.Lookup(beneficiary.BankDest); ok {
+		beneficiary.BankDest = bank.Code
+	}
+	if description := strings.TrimSpace(req.Description); description != "" {
+		beneficiary.Description = null.StringFrom(description)
+	}
+
+	fieldErrors := domain.ValidateBeneficiary(beneficiary.Detail(0), banks)
+	if len(fieldErrors) > 0 {
+		return uuid.Nil, &domain.ValidationError{
+			Message: "Validation failed",
+			Errors:  fieldErrors,
+		}
+	}
+
+	err = s.beneficiaryRepo.CreateBeneficiary(ctx, beneficiary)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return beneficiary.ID, nil
+}
+
+func (s *BeneficiaryService) GetBeneficiaries(ctx context.Context, accountNumber string) ([]domain.Beneficiary, error) {
+	return s.beneficiaryRepo.GetBeneficiaries(ctx, accountNumber)
+}
+
+// CreateTemplate saves a batch template over beneficiaries of the corporate.
+func (s *BeneficiaryService) CreateTemplate(ctx context.Context, req domain.TemplateRequest) (uuid.UUID, error) {
+	if !canManage(req.Role) {
+		return uuid.Nil, domain.ErrRoleNotAllowed
+	}
+
+	banks, err := s.bankService.GetDirectory(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	var fieldErrors []domain.FieldError
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		fieldErrors = append(fieldErrors, domain.FieldError{Field: "name", Message: "name is required"})
+	} else if len(req.Name) > 100 {
+		fieldErrors = append(fieldErrors, domain.FieldError{Field: "name", Message: "name must be at most 100 characters"})
+	}
+
+	req.FromAccount = strings.TrimSpace(req.FromAccount)
+	allowed, err := s.accountService.IsSourceAccountAllowed(ctx, req.AccountNumber, req.FromAccount)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if !allowed {
+		fieldErrors = append(fieldErrors, domain.FieldError{Field: "from_account", Message: "from_account is not an account of your corporate"})
+	}
+
+	if len(req.Items) == 0 {
+		fieldErrors = append(fieldErrors, domain.FieldError{Field: "items", Message: "template needs at least one beneficiary"})
+	}
+
+	ids := make([]uuid.UUID, len(req.Items))
+	for i, item := range req.Items {
+		ids[i] = item.BeneficiaryID
+	}
+	beneficiaries, err := s.beneficiaryRepo.GetBeneficiariesByID(ctx, req.AccountNumber, ids)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	saved := map[uuid.UUID]domain.Beneficiary{}
+	for _, beneficiary := range beneficiaries {
+		saved[beneficiary.ID] = beneficiary
+	}
+
+	template := domain.TransferTemplate{
+		ID:            uuid.New(),
+		AccountNumber: req.AccountNumber,
+		Name:          req.Name,
+		FromAccount:   req.FromAccount,
+		CreatedBy:     req.UserID,
+	}
+	seen := map[uuid.UUID]bool{}
+	for i, itemRequest := range req.Items {
+		beneficiary, ok := saved[itemRequest.BeneficiaryID]
+		if !ok {
+			fieldErrors = append(fieldErrors, domain.FieldError{Line: i + 1, Field: "beneficiary_id", Message: "unknown beneficiary " + itemRequest.BeneficiaryID.String()})
+			continue
+		}
+		if seen[beneficiary.ID] {
+			fieldErrors = append(fieldErrors, domain.FieldError{Line: i + 1, Field: "beneficiary_id", Message: "beneficiary is listed more than once"})
+			continue
+		}
+		seen[beneficiary.ID] = true
+
+		item := domain.TemplateItem{
+			Beneficiary: beneficiary,
+			Amount:      itemRequest.Amount,
+		}
+		if description := strings.TrimSpace(itemRequest.Description); description != "" {
+			item.Description = null.StringFrom(description)
+		}
+
+		// a template amount is optional, but must be valid when given
+		if item.Amount != 0 {
+			for _, fieldError := range domain.ValidateTransactionDetail(templateItemDetail(item, item.Amount), banks) {
+				fieldError.Line = i + 1
+				fieldErrors = append(fieldErrors, fieldError)
+			}
+		}
+		template.Items = append(template.Items, item)
+	}
+
+	if len(fieldErrors) > 0 {
+		return uuid.Nil, &domain.ValidationError{
+			Message: "Validation failed",
+			Errors:  fieldErrors,
+		}
+	}
+
+	err = s.beneficiaryRepo.CreateTemplate(ctx, template)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return template.ID, nil
+}
+
+func (s *BeneficiaryService) GetTemplates(ctx context.Context, accountNumber string) ([]domain.TransferTemplate, error) {
+	return s.beneficiaryRepo.GetTemplates(ctx, accountNumber)
+}
+
+func (s *BeneficiaryService) GetTemplate(ctx context.Context, accountNumber string, id uuid.UUID) (domain.TransferTemplate, error) {
+	return s.beneficiaryRepo.GetTemplateByID(ctx, accountNumber, id)
+}
+
+// TemplateTransactionDetails turns a template into transfer rows, taking each
+// amount from amounts or else from the template. Every row is checked with
+// the same rules as an uploaded row, with the item position as line number.
+func (s *BeneficiaryService) TemplateTransactionDetails(ctx context.Context, template domain.TransferTemplate, amounts map[uuid.UUID]domain.Money) ([]domain.TransactionDetail, error) {
+	banks, err := s.bankService.GetDirectory(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var details []domain.TransactionDetail
+	var fieldErrors []domain.FieldError
+
+	inTemplate := map[uuid.UUID]bool{}
+	for i, item := range template.Items {
+		inTemplate[item.Beneficiary.ID] = true
+
+		amount, ok := amounts[item.Beneficiary.ID]
+		if !ok {
+			amount = item.Amount
+		}
+		if amount == 0 {
+			fieldErrors = append(fieldErrors, domain.FieldError{
+				Line:    i + 1,
+				Field:   "amount",
+				Message: fmt.Sprintf("amount is required for beneficiary %s", item.Beneficiary.ID),
+			})
+			continue
+		}
+
+		detail := templateItemDetail(item, amount)
+		for _, fieldError := range domain.ValidateTransactionDetail(detail, banks) {
+			fieldError.Line = i + 1
+			fieldErrors = append(fieldErrors, fieldError)
+		}
+		details = append(details, detail)
+	}
+
+	var unknownIDs []string
+	for id := range amounts {
+		if !inTemplate[id] {
+			unknownIDs = append(unknownIDs, id.String())
+		}
+	}
+	sort.Strings(unknownIDs)
+	for _, id := range unknownIDs {
+		fieldErrors = append(fieldErrors, domain.FieldError{
+			Field:   "amounts",
+			Message: fmt.Sprintf("beneficiary %s is not in the template", id),
+		})
+	}
+
+	if len(fieldErrors) > 0 {
+		return nil, &domain.ValidationError{
+			Message: "Invalid transaction template",
+			Errors:  fieldErrors,
+		}
+	}
+
+	return details, nil
+}
+
+func templateItemDetail(item domain.TemplateItem, amount domain.Money) domain.TransactionDetail {
+	detail := item.Beneficiary.Detail(amount)
+	if item.Description.Valid {
+		detail.Description = item.Description
+	}
+	return detail
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"batch-transaction/internal/domain"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	idempotencyStatusPending   = "pending"
+	idempotencyStatusCompleted = "completed"
+)
+
+// idempotencyRecord is the value stored in redis for each Idempotency-Key.
+type idempotencyRecord struct {
+	Status      string                           `json:"status"`
+	RequestHash string                           `json:"request_hash"`
+	Response    domain.TransactionUploadResponse `json:"response"`
+}
+
+// hashUploadRequest fingerprints the uploaded file together with the declared
+// totals and source account, then rewinds the file for parsing. A template
+// batch is fingerprinted by its template and amounts instead.
+func hashUploadRequest(req domain.TransactionUploadRequest) (string, error) {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\n%s\n%d\n%s\n%t\n%s\n", req.FromAccount, req.TotalAmount, req.TotalRecord, req.Currency, req.MixedCurrency, req.TransferDate.Format(domain.TransferDateLayout))
+
+	var currencies []string
+	for currency := range req.CurrencyTotals {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	for _, currency := range currencies {
+		fmt.Fprintf(hash, "%s:%s\n", currency, req.CurrencyTotals[currency])
+	}
+
+	if req.TemplateID != nil {
+		var beneficiaries []string
+		for id, amount := range req.Amounts {
+			beneficiaries = append(beneficiaries, fmt.Sprintf("%s:%s", id, amount))
+		}
+		sort.Strings(beneficiaries)
+		fmt.Fprintf(hash, "template:%s\n%s\n", req.TemplateID, strings.Join(beneficiaries, "\n"))
+		return hex.EncodeToString(hash.Sum(nil)), nil
+	}
+
+	file := *req.File
+	_, err := io.Copy(hash, file)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// reserveIdempotencyKey claims the key for a new request. When the key is
+// already taken it returns the stored response for an identical completed
+// request, or an error when the payload differs or the first request is
+// still running.
+func (s *TransactionService) reserveIdempotencyKey(ctx context.Context, key string, requestHash string) (domain.TransactionUploadResponse, bool, error) {
+	var response domain.TransactionUploadResponse
+
+	pending, err := json.Marshal(idempotencyRecord{
+		Status:      idempotencyStatusPending,
+		RequestHash: requestHash,
+	})
+	if err != nil {
+		return response, false, err
+	}
+
+	// a reservation outlives a crashed request only briefly, the completed
+	// response is kept for the full IDEMPOTENCY_KEY_TTL
+	reserved, err := s.redisClient.SetNX(ctx, key, string(pending), s.configInterface.GetIdempotencyPendingTTL())
+	if err != nil {
+		return response, false, err
+	}
+	if reserved {
+		return response, false, nil
+	}
+
+	stored, err := s.redisClient.Get(ctx, key)
+	if err != nil {
+		return response, false, err
+	}
+
+	var record idempotencyRecord
+	err = json.Unmarshal([]byte(stored), &record)
+	if err != nil {
+		return response, false, err
+	}
+
+	if record.RequestHash != requestHash {
+		return response, false, domain.ErrIdempotencyKeyConflict
+	}
+	if record.Status != idempotencyStatusCompleted {
+		return response, false, domain.ErrIdempotencyKeyInProgress
+	}
+
+	response = record.Response
+	response.Replayed = true
+	return response, true, nil
+}
+
+func (s *TransactionService) completeIdempotencyKey(ctx context.Context, key string, requestHash string, response domain.TransactionUploadResponse) error {
+	completed, err := json.Marshal(idempotencyRecord{
+		Status:      idempotencyStatusCompleted,
+		RequestHash: requestHash,
+		Response:    response,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.redisClient.Set(ctx, key, string(completed), s.configInterface.GetIdempotencyKeyTTL())
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"batch-transaction/internal/domain"
+	"context"
+	"strings"
+	"time"
+)
+
+type LimitService struct {
+	limitRepo domain.LimitRepository
+	userRepo  domain.UserRepository
+}
+
+func NewLimitService(limitRepo domain.LimitRepository, userRepo domain.UserRepository) *LimitService {
+	return &LimitService{
+		limitRepo: limitRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// CheckTransaction checks the rows of a new batch by maker against the limits
+// of the corporate and the maker. Approval checks the limits again, since the
+// daily usage may have grown in the meantime.
+func (s *LimitService) CheckTransaction(ctx context.Context, accountNumber string, maker string, details []domain.TransactionDetail, now time.Time) error {
+	limits, err := s.limitRepo.GetTransferLimits(ctx, accountNumber, maker)
+	if err != nil {
+		return err
+	}
+	if len(limits) == 0 {
+		return nil
+	}
+
+	usage, err := s.limitRepo.GetLimitUsage(ctx, accountNumber, maker, now.Add(-domain.LimitWindow))
+	if err != nil {
+		return err
+	}
+
+	return domain.CheckTransferLimits(limits, details, usage)
+}
+
+func (s *LimitService) GetTransferLimits(ctx context.Context, accountNumber string, role domain.Role) ([]domain.TransferLimit, error) {
+	if role != domain.Admin {
+		return nil, domain.ErrRoleNotAllowed
+	}
+
+	return s.limitRepo.ListTransferLimits(ctx, accountNumber)
+}
+
+// SaveTransferLimit sets the limits of the admin's corporate in a currency, or
+// those of one of its makers when UserID is given.
+func (s *LimitService) SaveTransferLimit(ctx context.Context, req domain.TransferLimitRequest) error {
+	if req.Role != domain.Admin {
+		return domain.ErrRoleNotAllowed
+	}
+
+	limit := domain.TransferLimit{
+		AccountNumber: req.AccountNumber,
+		UserID:        strings.TrimSpace(req.UserID),
+		Currency:      domain.NormalizeCurrency(req.Currency, domain.DefaultCurrency),
+		MaxPerRow:     req.MaxPerRow,
+		MaxPerBatch:   req.MaxPerBatch,
+		DailyLimit:    req.DailyLimit,
+	}
+
+	var fieldErrors []domain.FieldError
+	_, currencyValid := domain.CurrencyDecimals(limit.Currency)
+	if !currencyValid {
+		fieldErrors = append(fieldErrors, domain.FieldError{Field: "currency", Message: "unsupported currency " + limit.Currency})
+	}
+	for _, amount := range []struct {
+		field string
+		value domain.Money
+	}{
+		{"max_per_row", limit.MaxPerRow},
+		{"max_per_batch", limit.MaxPerBatch},
+		{"daily_limit", limit.DailyLimit},
+	} {
+		if amount.value < 0 {
+			fieldErrors = append(fieldErrors, domain.FieldError{Field: amount.field, Message: amount.field + " must not be negative, use 0 for no limit"})
+		} else if currencyValid {
+			if err := domain.ValidateCurrencyAmount(limit.Currency, amount.value); err != nil {
+				fieldErrors = append(fieldErrors, domain.FieldError{Field: amount.field, Message: err.Error()})
+			}
+		}
+	}
+
+	if limit.UserID != "" {
+		makers, err := s.userRepo.GetUsersByAccountNumber(ctx, req.AccountNumber, domain.Maker)
+		if err != nil {
+			return err
+		}
+		found := false
+		for _, maker := range makers {
+			if maker.UserID == limit.UserID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fieldErrors = append(fieldErrors, domain.FieldError{Field: "user_id", Message: "user_id is not a maker of your corporate"})
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return &domain.ValidationError{
+			Message: "Validation failed",
+			Errors:  fieldErrors,
+		}
+	}
+
+	return s.limitRepo.SaveTransferLimit(ctx, limit)
+}
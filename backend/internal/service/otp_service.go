@@ -1,50 +1,217 @@
 package service
 
 import (
+	"batch-transaction/internal/config"
 	"batch-transaction/internal/database"
+	"batch-transaction/internal/domain"
 	"batch-transaction/internal/otp"
 	"context"
+	"crypto/subtle"
+	"strings"
 	"time"
 )
 
 type OTPService struct {
-	otpGenerator otp.OTPGenerator
-	redisClient  database.RedisClient
+	otpGenerator    otp.OTPGenerator
+	otpSender       domain.OTPSender
+	redisClient     database.RedisClient
+	configInterface config.ConfigInterface
 }
 
-func NewOTPService(redisClient database.RedisClient) *OTPService {
+func NewOTPService(redisClient database.RedisClient, otpSender domain.OTPSender, configInterface config.ConfigInterface) *OTPService {
 	return &OTPService{
-		otpGenerator: otp.NewOTPGenerator(),
-		redisClient:  redisClient,
+		otpGenerator:    otp.NewOTPGenerator(),
+		otpSender:       otpSender,
+		redisClient:     redisClient,
+		configInterface: configInterface,
 	}
 }
 
-func (s *OTPService) SendOTP(email string) (otpCode string, err error) {
+// SendOTP issues the registration OTP for the email of recipient, replacing
+// any code issued before, and delivers it. Requests are limited per email and
+// per client IP within OTP_SEND_WINDOW, and a locked email gets no code. The
+// code is only returned with OTP_DEV_MODE, and is empty otherwise.
+func (s *OTPService) SendOTP(ctx context.Context, recipient domain.OTPRecipient, clientIP string) (otpCode string, err error) {
+	email := recipient.Email
+	locked, err := s.redisClient.Exists(ctx, "otp:lock:"+email)
+	if err != nil {
+		return "", err
+	}
+	if locked {
+		return "", domain.ErrOTPLocked
+	}
+
+	window := s.configInterface.GetOTPSendWindow()
+	sent, err := s.redisClient.IncrWithExpiry(ctx, "otp:send:email:"+email, window)
+	if err != nil {
+		return "", err
+	}
+	if sent > int64(s.configInterface.GetOTPSendLimitPerEmail()) {
+		return "", domain.ErrOTPRateLimited
+	}
+	sent, err = s.redisClient.IncrWithExpiry(ctx, "otp:send:ip:"+clientIP, window)
+	if err != nil {
+		return "", err
+	}
+	if sent > int64(s.configInterface.GetOTPSendLimitPerIP()) {
+		return "", domain.ErrOTPRateLimited
+	}
+
 	otp, err := s.otpGenerator.GenerateOTP(email)
 	if err != nil {
 		return "", err
 	}
 
-	// send otp to email
+	err = s.redisClient.Set(ctx, "otp:"+email, otp, s.configInterface.GetOTPTTL())
+	if err != nil {
+		return "", err
+	}
 
-	// store otp to redis
-	err = s.redisClient.Set(context.Background(), "otp:"+email, otp, 300*time.Second)
+	err = s.deliverOTP(ctx, recipient, "registration", otp)
 	if err != nil {
+		s.redisClient.Del(ctx, "otp:"+email)
 		return "", err
 	}
 
+	if !s.configInterface.GetOTPDevMode() {
+		return "", nil
+	}
 	return otp, nil
 }
 
-func (s *OTPService) ValidateOTP(email string, otp string) error {
-	otpFromRedis, err := s.redisClient.Get(context.Background(), "otp:"+email)
+// ValidateOTP uses up the registration OTP of email. Every attempt is counted,
+// and after OTP_MAX_ATTEMPTS attempts within OTP_LOCKOUT_DURATION the code is
+// discarded and the email is locked for OTP_LOCKOUT_DURATION.
+func (s *OTPService) ValidateOTP(ctx context.Context, email string, otp string) error {
+	locked, err := s.redisClient.Exists(ctx, "otp:lock:"+email)
 	if err != nil {
 		return err
 	}
+	if locked {
+		return domain.ErrOTPLocked
+	}
 
-	if otpFromRedis != otp {
+	// counting before comparing keeps concurrent guesses within the limit
+	lockout := s.configInterface.GetOTPLockoutDuration()
+	maxAttempts := int64(s.configInterface.GetOTPMaxAttempts())
+	attempts, err := s.redisClient.IncrWithExpiry(ctx, "otp:attempts:"+email, lockout)
+	if err != nil {
 		return err
 	}
+	if attempts > maxAttempts {
+		return domain.ErrOTPLocked
+	}
+
+	used, err := s.redisClient.DelIfEqual(ctx, "otp:"+email, otp)
+	if err != nil {
+		return err
+	}
+	if used {
+		return s.redisClient.Del(ctx, "otp:attempts:"+email)
+	}
+
+	if attempts == maxAttempts {
+		err = s.redisClient.Set(ctx, "otp:lock:"+email, "1", lockout)
+		if err != nil {
+			return err
+		}
+		err = s.redisClient.Del(ctx, "otp:"+email, "otp:attempts:"+email)
+		if err != nil {
+			return err
+		}
+		return domain.ErrOTPLocked
+	}
 
+	return domain.ErrInvalidOTP
+}
+
+// SendActionOTP issues a one-time code confirming action by userID, replacing
+// any code issued for it before, and delivers it to recipient. The code is
+// only accepted for the same binding, such as the amount the action applies
+// to, and expires after ttl. Like SendOTP, requests are limited to
+// OTP_SEND_LIMIT_PER_EMAIL per user within OTP_SEND_WINDOW and the code is
+// only returned with OTP_DEV_MODE.
+func (s *OTPService) SendActionOTP(ctx context.Context, userID string, action string, binding string, recipient domain.OTPRecipient, purpose string, ttl time.Duration) (string, error) {
+	sent, err := s.redisClient.IncrWithExpiry(ctx, "otp:send:user:"+userID, s.configInterface.GetOTPSendWindow())
+	if err != nil {
+		return "", err
+	}
+	if sent > int64(s.configInterface.GetOTPSendLimitPerEmail()) {
+		return "", domain.ErrOTPRateLimited
+	}
+
+	otp, err := s.otpGenerator.GenerateOTP(action)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.redisClient.Set(ctx, "otp:action:"+action, otp+"|"+binding, ttl)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.deliverOTP(ctx, recipient, purpose, otp)
+	if err != nil {
+		s.redisClient.Del(ctx, "otp:action:"+action)
+		return "", err
+	}
+
+	if !s.configInterface.GetOTPDevMode() {
+		return "", nil
+	}
+	return otp, nil
+}
+
+// deliverOTP sends code through the configured channel, retrying once when
+// the channel fails. A recipient without a contact for the channel is not
+// retried.
+func (s *OTPService) deliverOTP(ctx context.Context, recipient domain.OTPRecipient, purpose string, code string) error {
+	err := s.otpSender.SendOTP(ctx, recipient, purpose, code)
+	if err == domain.ErrOTPEmailRequired || err == domain.ErrOTPPhoneNumberRequired {
+		return err
+	}
+	if err != nil && ctx.Err() == nil {
+		err = s.otpSender.SendOTP(ctx, recipient, purpose, code)
+	}
+	if err != nil {
+		return &domain.OTPDeliveryError{Err: err}
+	}
+	return nil
+}
+
+// CheckActionOTP checks the code issued for action without using it up, so
+// the action can still fail and be tried again with the same code. A wrong
+// code uses it up, so it cannot be guessed and has to be requested again.
+func (s *OTPService) CheckActionOTP(ctx context.Context, action string, binding string, otp string) error {
+	stored, err := s.redisClient.Get(ctx, "otp:action:"+action)
+	if err == database.ErrKeyNotFound {
+		return domain.ErrInvalidOTP
+	}
+	if err != nil {
+		return err
+	}
+
+	storedOTP, storedBinding, _ := strings.Cut(stored, "|")
+	if subtle.ConstantTimeCompare([]byte(storedOTP), []byte(otp)) != 1 || storedBinding != binding {
+		err = s.redisClient.Del(ctx, "otp:action:"+action)
+		if err != nil {
+			return err
+		}
+		return domain.ErrInvalidOTP
+	}
+
+	return nil
+}
+
+// ConsumeActionOTP uses up a code accepted by CheckActionOTP once the action
+// is done.
+func (s *OTPService) ConsumeActionOTP(ctx context.Context, action string, binding string, otp string) error {
+	used, err := s.redisClient.DelIfEqual(ctx, "otp:action:"+action, otp+"|"+binding)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return domain.ErrInvalidOTP
+	}
 	return nil
 }
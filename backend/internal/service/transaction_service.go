@@ -1,103 +1,854 @@
 package service
 
 import (
+	"batch-transaction/internal/config"
+	"batch-transaction/internal/database"
 	"batch-transaction/internal/domain"
+	"batch-transaction/internal/parser"
 	"context"
-	"encoding/csv"
-	"io"
-	"mime/multipart"
-	"strconv"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	null "gopkg.in/guregu/null.v4"
 )
 
 type TransactionService struct {
-	transactionRepo domain.TransactionRepository
+	transactionRepo    domain.TransactionRepository
+	userRepo           domain.UserRepository
+	accountService     *AccountService
+	beneficiaryService *BeneficiaryService
+	bankService        *BankService
+	screener           domain.Screener
+	limitService       *LimitService
+	otpService         *OTPService
+	redisClient        database.RedisClient
+	configInterface    config.ConfigInterface
 }
 
-func NewTransactionService(transactionRepo domain.TransactionRepository) *TransactionService {
+func NewTransactionService(transactionRepo domain.TransactionRepository, userRepo domain.UserRepository, accountService *AccountService, beneficiaryService *BeneficiaryService, bankService *BankService, screener domain.Screener, limitService *LimitService, otpService *OTPService, redisClient database.RedisClient, configInterface config.ConfigInterface) *TransactionService {
 	return &TransactionService{
-		transactionRepo: transactionRepo,
+		transactionRepo:    transactionRepo,
+		userRepo:           userRepo,
+		accountService:     accountService,
+		beneficiaryService: beneficiaryService,
+		bankService:        bankService,
+		screener:           screener,
+		limitService:       limitService,
+		otpService:         otpService,
+		redisClient:        redisClient,
+		configInterface:    configInterface,
 	}
 }
 
-func (s *TransactionService) GetTransactionSummary(ctx context.Context) (domain.TransactionSummaryResult, error) {
-	return s.transactionRepo.GetTransactionSummary(ctx)
+func (s *TransactionService) GetTransactionSummary(ctx context.Context, accountNumber string) (domain.TransactionSummaryResult, error) {
+	return s.transactionRepo.GetTransactionSummary(ctx, accountNumber)
 }
 
-func (s *TransactionService) UpdateTransaction(ctx context.Context, trx domain.Transaction) error {
-	if trx.TransactionStatus != string(domain.Approved) && trx.TransactionStatus != string(domain.Rejected) {
-		return domain.ErrInvalidTransactionStatus
+// UpdateTransaction moves a batch to the requested status. Approvers other
+// than the batch's maker may approve or reject it, and the maker may cancel it
+// while it waits for approval. Other statuses are only set by the system.
+// An approval only finalizes the batch once its required approvals are met,
+// and needs an OTP from SendApprovalOTP when the total of any of its
+// currencies reaches APPROVAL_OTP_MIN_AMOUNTS.
+// With INQUIRY_BLOCK_MISMATCH set, a batch is only approved once the account
+// names of all its rows were checked without a mismatch. A batch held for
+// compliance review is cleared for approval or rejected by a Compliance user,
+// whatever corporate it belongs to.
+func (s *TransactionService) UpdateTransaction(ctx context.Context, req domain.UpdateTransactionRequest) (domain.UpdateTransactionResponse, error) {
+	var response domain.UpdateTransactionResponse
+
+	target := domain.TransactionStatus(req.Status)
+	if target != domain.Approved && target != domain.Rejected && target != domain.Cancelled && target != domain.WaitingApproval {
+		return response, domain.ErrInvalidTransactionStatus
+	}
+	if target == domain.Rejected && strings.TrimSpace(req.Reason) == "" {
+		return response, domain.ErrRejectionReasonRequired
+	}
+	if target == domain.WaitingApproval && strings.TrimSpace(req.Reason) == "" {
+		return response, domain.ErrClearanceReasonRequired
+	}
+
+	accountNumber, err := s.transactionAccountNumber(ctx, req.Role, req.AccountNumber, req.TransactionID)
+	if err != nil {
+		return response, err
+	}
+	req.AccountNumber = accountNumber
+	existing, err := s.transactionRepo.GetTransactionByID(ctx, req.AccountNumber, req.TransactionID)
+	if err != nil {
+		return response, err
+	}
+	current := domain.TransactionStatus(existing.TransactionStatus)
+
+	switch {
+	case target == domain.Cancelled:
+		if req.Role != domain.Maker {
+			return response, domain.ErrRoleNotAllowed
+		}
+		if existing.Maker != req.UserID {
+			return response, domain.ErrNotMaker
+		}
+	case target == domain.WaitingApproval || current == domain.ComplianceReview:
+		// batches held for review are cleared or rejected by compliance only
+		if req.Role != domain.Compliance {
+			return response, domain.ErrRoleNotAllowed
+		}
+	default:
+		if req.Role != domain.Approver {
+			return response, domain.ErrRoleNotAllowed
+		}
+		if existing.Maker == req.UserID {
+			return response, domain.ErrSelfApproval
+		}
+	}
+
+	if !current.CanTransitionTo(target) {
+		return response, domain.ErrInvalidTransition
+	}
+
+	now := time.Now()
+	if (target == domain.Approved || target == domain.WaitingApproval) && s.configInterface.GetTransferCalendar().IsOverdue(existing.TransferDate, now) {
+		return response, domain.ErrTransferDateExpired
+	}
+	if target == domain.Approved && s.configInterface.GetInquiryBlockMismatch() {
+		details, err := s.transactionRepo.GetTransactionDetailByTransactionID(ctx, req.AccountNumber, req.TransactionID)
+		if err != nil {
+			return response, err
+		}
+		inquiry := domain.SummarizeInquiries(details)
+		if inquiry.Mismatch > 0 {
+			return response, domain.ErrInquiryMismatch
+		}
+		if inquiry.Pending > 0 {
+			return response, domain.ErrInquiryPending
+		}
+	}
+	event := domain.TransactionEvent{
+		TransactionID:  req.TransactionID,
+		Actor:          req.UserID,
+		ActorRole:      req.Role,
+		PreviousStatus: &current,
+		NewStatus:      target,
+		CreatedAt:      now,
+	}
+	if reason := strings.TrimSpace(req.Reason); reason != "" {
+		event.Reason = null.StringFrom(reason)
+	}
+
+	otpRequired := target == domain.Approved && s.requiresApprovalOTP(existing)
+	otpCode := strings.TrimSpace(req.OTPCode)
+	if otpRequired {
+		if otpCode == "" {
+			return response, domain.ErrOTPRequired
+		}
+		err = s.otpService.CheckActionOTP(ctx, approvalOTPAction(req.UserID, req.TransactionID), approvalOTPBinding(existing), otpCode)
+		if err != nil {
+			return response, err
+		}
 	}
-	return s.transactionRepo.UpdateTransaction(ctx, trx)
+
+	if target == domain.Approved {
+		progress, err := s.transactionRepo.ApproveTransaction(ctx, req.AccountNumber, domain.TransactionApproval{
+			TransactionID: req.TransactionID,
+			Approver:      req.UserID,
+			ApprovedAt:    now,
+		}, event)
+		if err != nil {
+			return response, err
+		}
+		// the code is only used up by a recorded approval, so a failed one can
+		// be retried with it. A code that could not be used up is harmless, it
+		// only approves for an approver who already approved.
+		if otpRequired {
+			s.otpService.ConsumeActionOTP(context.WithoutCancel(ctx), approvalOTPAction(req.UserID, req.TransactionID), approvalOTPBinding(existing), otpCode)
+		}
+
+		if progress.Approved {
+			response.Message = "Transaction approved"
+		} else {
+			response.Message = fmt.Sprintf("Approval recorded, %d of %d approvals received", progress.Approvals, progress.RequiredApprovals)
+		}
+		return response, nil
+	}
+
+	err = s.transactionRepo.UpdateTransactionStatus(ctx, req.AccountNumber, event)
+	if err != nil {
+		return response, err
+	}
+
+	response.Message = "Transaction updated successfully"
+	return response, nil
 }
 
+// SendApprovalOTP issues the one-time code the approver needs to approve a
+// batch waiting for approval and delivers it to the approver. The code is
+// bound to the batch and its amounts, can be used once and expires after
+// APPROVAL_OTP_TTL. A batch below the APPROVAL_OTP_MIN_AMOUNTS thresholds
+// needs no code, so none is sent.
+func (s *TransactionService) SendApprovalOTP(ctx context.Context, req domain.ApprovalOTPRequest) (domain.ApprovalOTPResponse, error) {
+	var response domain.ApprovalOTPResponse
+
+	if req.Role != domain.Approver {
+		return response, domain.ErrRoleNotAllowed
+	}
+
+	transaction, err := s.transactionRepo.GetTransactionByID(ctx, req.AccountNumber, req.TransactionID)
+	if err != nil {
+		return response, err
+	}
+	if transaction.Maker == req.UserID {
+		return response, domain.ErrSelfApproval
+	}
+	if domain.TransactionStatus(transaction.TransactionStatus) != domain.WaitingApproval {
+		return response, domain.ErrInvalidTransition
+	}
+	if !s.requiresApprovalOTP(transaction) {
+		response.Message = "No OTP required"
+		return response, nil
+	}
+
+	approver, err := s.userRepo.GetUserByUserID(ctx, req.UserID)
+	if err != nil {
+		return response, err
+	}
+	recipient := domain.OTPRecipient{
+		Email:       approver.Email,
+		PhoneNumber: approver.PhoneNumber,
+	}
+
+	ttl := s.configInterface.GetApprovalOTPTTL()
+	purpose := "approving transaction " + req.TransactionID.String()
+	otp, err := s.otpService.SendActionOTP(ctx, req.UserID, approvalOTPAction(req.UserID, req.TransactionID), approvalOTPBinding(transaction), recipient, purpose, ttl)
+	if err != nil {
+		return response, err
+	}
+
+	response = domain.ApprovalOTPResponse{
+		Message:     "Success send OTP",
+		OTPRequired: true,
+		OTP:         otp,
+		ExpiresIn:   int(ttl.Seconds()),
+	}
+	return response, nil
+}
+
+// requiresApprovalOTP reports whether approving transaction needs an OTP,
+// which is when the total of any of its currencies reaches the threshold of
+// that currency. A currency without a threshold always needs one.
+func (s *TransactionService) requiresApprovalOTP(transaction domain.Transaction) bool {
+	thresholds := s.configInterface.GetApprovalOTPMinAmounts()
+	totals := transaction.CurrencyTotals
+	if len(totals) == 0 {
+		totals = []domain.CurrencyTotal{{Currency: transaction.Currency, TotalAmount: transaction.TotalAmount}}
+	}
+	for _, total := range totals {
+		if total.TotalAmount >= thresholds[total.Currency] {
+			return true
+		}
+	}
+	return false
+}
+
+func approvalOTPAction(userID string, transactionID uuid.UUID) string {
+	return "approve:" + transactionID.String() + ":" + userID
+}
+
+// approvalOTPBinding describes the amounts of a batch, so an approval OTP is
+// not accepted for a batch whose amounts differ from those it was issued for.
+func approvalOTPBinding(transaction domain.Transaction) string {
+	binding := transaction.Currency + " " + transaction.TotalAmount.String()
+	for _, currencyTotal := range transaction.CurrencyTotals {
+		binding += fmt.Sprintf(";%s %s", currencyTotal.Currency, currencyTotal.TotalAmount)
+	}
+	return binding
+}
+
+// GetTransactionList lists the batches of the caller's corporate. Compliance
+// users list the batches of every corporate held for compliance review.
 func (s *TransactionService) GetTransactionList(ctx context.Context, param domain.TransactionListParam) ([]domain.Transaction, domain.Pagination, error) {
+	param.AllCorporates = false
+	if param.Role == domain.Compliance {
+		param.AllCorporates = true
+		param.StatusFilter = []domain.TransactionStatus{domain.ComplianceReview}
+	}
 	return s.transactionRepo.GetTransactionList(ctx, param)
 }
 
-func (s *TransactionService) GetTransactionDetail(ctx context.Context, transactionID uuid.UUID) ([]domain.TransactionDetail, error) {
-	return s.transactionRepo.GetTransactionDetailByTransactionID(ctx, transactionID)
+// transactionAccountNumber returns the corporate whose batch the caller may
+// see. Compliance users are bank staff, so for them it is the corporate of
+// the batch as long as it is held for compliance review.
+func (s *TransactionService) transactionAccountNumber(ctx context.Context, role domain.Role, accountNumber string, transactionID uuid.UUID) (string, error) {
+	if role != domain.Compliance {
+		return accountNumber, nil
+	}
+	return s.transactionRepo.GetTransactionAccountNumber(ctx, transactionID, domain.ComplianceReview)
+}
+
+// GetTransactionDetail returns the rows of a batch together with its approval
+// progress. Pending approvers are the corporate's approvers, other than the
+// maker, who have not approved yet.
+func (s *TransactionService) GetTransactionDetail(ctx context.Context, accountNumber string, role domain.Role, transactionID uuid.UUID) (domain.TransactionDetailResponse, error) {
+	var response domain.TransactionDetailResponse
+
+	accountNumber, err := s.transactionAccountNumber(ctx, role, accountNumber, transactionID)
+	if err != nil {
+		return response, err
+	}
+	transaction, err := s.transactionRepo.GetTransactionByID(ctx, accountNumber, transactionID)
+	if err != nil {
+		return response, err
+	}
+
+	details, err := s.transactionRepo.GetTransactionDetailByTransactionID(ctx, accountNumber, transactionID)
+	if err != nil {
+		return response, err
+	}
+
+	approvals, err := s.transactionRepo.GetTransactionApprovals(ctx, accountNumber, transactionID)
+	if err != nil {
+		return response, err
+	}
+
+	pendingApprovers := []string{}
+	if transaction.TransactionStatus == string(domain.WaitingApproval) {
+		approvers, err := s.userRepo.GetUsersByAccountNumber(ctx, transaction.AccountNumber, domain.Approver)
+		if err != nil {
+			return response, err
+		}
+
+		approved := map[string]bool{}
+		for _, approval := range approvals {
+			approved[approval.Approver] = true
+		}
+		for _, approver := range approvers {
+			if approver.UserID != transaction.Maker && !approved[approver.UserID] {
+				pendingApprovers = append(pendingApprovers, approver.UserID)
+			}
+		}
+	}
+
+	response.Data = details
+	if response.Data == nil {
+		response.Data = []domain.TransactionDetail{}
+	}
+	response.Inquiry = domain.SummarizeInquiries(details)
+	response.ScreeningHits, err = s.transactionRepo.GetScreeningHits(ctx, accountNumber, transactionID)
+	if err != nil {
+		return response, err
+	}
+	if response.ScreeningHits == nil {
+		response.ScreeningHits = []domain.ScreeningHit{}
+	}
+	response.Approval = domain.ApprovalStatus{
+		RequiredApprovals: transaction.RequiredApprovals,
+		Approvals:         approvals,
+		PendingApprovers:  pendingApprovers,
+	}
+	if response.Approval.Approvals == nil {
+		response.Approval.Approvals = []domain.TransactionApproval{}
+	}
+
+	response.ParentTransactionID = transaction.ParentTransactionID
+	response.RetryTransactionIDs, err = s.transactionRepo.GetRetryTransactionIDs(ctx, accountNumber, transactionID)
+	if err != nil {
+		return response, err
+	}
+	if response.RetryTransactionIDs == nil {
+		response.RetryTransactionIDs = []uuid.UUID{}
+	}
+
+	return response, nil
+}
+
+// RetryFailedTransaction copies the failed rows of a processed batch into a
+// new batch linked to it, which goes through approval like an upload.
+func (s *TransactionService) RetryFailedTransaction(ctx context.Context, req domain.RetryTransactionRequest) (domain.TransactionUploadResponse, error) {
+	var response domain.TransactionUploadResponse
+
+	if req.Role != domain.Maker {
+		return response, domain.ErrRoleNotAllowed
+	}
+
+	parent, err := s.transactionRepo.GetTransactionByID(ctx, req.AccountNumber, req.TransactionID)
+	if err != nil {
+		return response, err
+	}
+	status := domain.TransactionStatus(parent.TransactionStatus)
+	if status != domain.PartiallyCompleted && status != domain.Failed {
+		return response, domain.ErrInvalidTransition
+	}
+
+	details, err := s.transactionRepo.GetTransactionDetailByTransactionID(ctx, req.AccountNumber, req.TransactionID)
+	if err != nil {
+		return response, err
+	}
+
+	now := time.Now()
+	transactionGUID := uuid.New()
+	transaction := domain.Transaction{
+		ID:                  transactionGUID,
+		AccountNumber:       parent.AccountNumber,
+		Currency:            parent.Currency,
+		FromAccount:         parent.FromAccount,
+		Maker:               req.UserID,
+		TransferDate:        s.configInterface.GetTransferCalendar().EarliestTransferDate(now),
+		CreatedAt:           now,
+		TransactionStatus:   string(domain.WaitingApproval),
+		ParentTransactionID: &parent.ID,
+	}
+
+	var retryDetails []domain.TransactionDetail
+	for _, detail := range details {
+		if detail.Status != domain.DetailFailed {
+			continue
+		}
+		retryDetails = append(retryDetails, domain.TransactionDetail{
+			TransactionID:   transactionGUID,
+			BankDest:        detail.BankDest,
+			AccountIDDest:   detail.AccountIDDest,
+			AccountNameDest: detail.AccountNameDest,
+			Amount:          detail.Amount,
+			Currency:        detail.Currency,
+			Description:     detail.Description,
+			TransferDate:    transaction.TransferDate,
+			Status:          domain.DetailPending,
+		})
+		if detail.Currency == transaction.Currency {
+			transaction.TotalAmount += detail.Amount
+		}
+	}
+	if len(retryDetails) == 0 {
+		return response, domain.ErrInvalidTransition
+	}
+	err = s.limitService.CheckTransaction(ctx, transaction.AccountNumber, transaction.Maker, retryDetails, now)
+	if err != nil {
+		return response, err
+	}
+	transaction.TotalRecord = len(retryDetails)
+	transaction.CurrencyTotals = domain.SumByCurrency(retryDetails)
+	transaction.MixedCurrency = len(transaction.CurrencyTotals) > 1
+
+	transaction.RequiredApprovals, err = s.transactionRepo.GetRequiredApprovals(ctx, transaction.AccountNumber, transaction.CurrencyTotals)
+	if err != nil {
+		return response, err
+	}
+	transaction.Fingerprint, transaction.BeneficiaryFingerprint = fingerprintTransactionDetails(retryDetails)
+
+	hits := s.screenTransactionDetails(&transaction, retryDetails)
+
+	err = s.transactionRepo.CreateTransaction(ctx, transaction, retryDetails, hits)
+	if err != nil {
+		return response, err
+	}
+
+	response = domain.TransactionUploadResponse{
+		TransactionID: transactionGUID,
+		TotalRecord:   transaction.TotalRecord,
+		TotalAmount:   transaction.TotalAmount,
+		Currency:      transaction.Currency,
+		TransferDate:  transaction.TransferDate,
+		Message:       "Retry transaction created successfully",
+
+		CurrencyTotals: transaction.CurrencyTotals,
+	}
+	if transaction.TransactionStatus == string(domain.ComplianceReview) {
+		response.Message = "Retry transaction created and held for compliance review"
+	}
+	return response, nil
+}
+
+// GetTransactionHistory returns every recorded status change of a batch, oldest
+// first.
+func (s *TransactionService) GetTransactionHistory(ctx context.Context, accountNumber string, role domain.Role, transactionID uuid.UUID) ([]domain.TransactionEvent, error) {
+	accountNumber, err := s.transactionAccountNumber(ctx, role, accountNumber, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.transactionRepo.GetTransactionByID(ctx, accountNumber, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.transactionRepo.GetTransactionEvents(ctx, accountNumber, transactionID)
+}
+
+// CreateTransaction stores an uploaded batch. When the request carries an
+// idempotency key, a retry with the same key and payload returns the original
+// response instead of creating a second batch.
+func (s *TransactionService) CreateTransaction(ctx context.Context, req domain.TransactionUploadRequest) (domain.TransactionUploadResponse, error) {
+	if req.Role != domain.Maker {
+		return domain.TransactionUploadResponse{}, domain.ErrRoleNotAllowed
+	}
+	if req.IdempotencyKey == "" {
+		return s.createTransaction(ctx, req)
+	}
+	if len(req.IdempotencyKey) > 255 {
+		return domain.TransactionUploadResponse{}, domain.ErrInvalidIdempotencyKey
+	}
+
+	requestHash, err := hashUploadRequest(req)
+	if err != nil {
+		return domain.TransactionUploadResponse{}, err
+	}
+
+	idempotencyKey := "idempotency:transaction:" + req.UserID + ":" + req.IdempotencyKey
+	replay, found, err := s.reserveIdempotencyKey(ctx, idempotencyKey, requestHash)
+	if err != nil || found {
+		return replay, err
+	}
+
+	// the key must be released or completed even when the client gave up,
+	// since that is when it retries
+	response, err := s.createTransaction(ctx, req)
+	if err != nil {
+		// release the key so the maker can retry after fixing the request
+		s.redisClient.Del(context.WithoutCancel(ctx), idempotencyKey)
+		return response, err
+	}
+
+	// the batch is stored, so failing the request now would have the client
+	// retry into a second batch once the reservation expires
+	err = s.completeIdempotencyKey(context.WithoutCancel(ctx), idempotencyKey, requestHash, response)
+	if err != nil {
+		log.Println("transaction: complete idempotency key", idempotencyKey, ":", err)
+	}
+
+	return response, nil
 }
 
-func (s *TransactionService) CreateTransaction(ctx context.Context, req domain.TransactionUploadRequest) error {
+func (s *TransactionService) createTransaction(ctx context.Context, req domain.TransactionUploadRequest) (domain.TransactionUploadResponse, error) {
+	var response domain.TransactionUploadResponse
+
+	var template *domain.TransferTemplate
+	if req.TemplateID != nil {
+		found, err := s.beneficiaryService.GetTemplate(ctx, req.AccountNumber, *req.TemplateID)
+		if err == domain.ErrTemplateNotFound {
+			return response, &domain.ValidationError{
+				Message: "Validation failed",
+				Errors: []domain.FieldError{{
+					Field:   "template_id",
+					Message: err.Error(),
+				}},
+			}
+		}
+		if err != nil {
+			return response, err
+		}
+		if req.FromAccount == "" {
+			req.FromAccount = found.FromAccount
+		}
+		template = &found
+	}
+
+	allowed, err := s.accountService.IsSourceAccountAllowed(ctx, req.AccountNumber, req.FromAccount)
+	if err != nil {
+		return response, err
+	}
+	if !allowed {
+		return response, &domain.ValidationError{
+			Message: "Validation failed",
+			Errors: []domain.FieldError{{
+				Field:   "from_account",
+				Message: "from_account is not an account of your corporate",
+			}},
+		}
+	}
+
+	if _, ok := domain.CurrencyDecimals(req.Currency); !ok {
+		return response, &domain.ValidationError{
+			Message: "Validation failed",
+			Errors: []domain.FieldError{{
+				Field:   "currency",
+				Message: "unsupported currency " + req.Currency,
+			}},
+		}
+	}
+	if err := domain.ValidateCurrencyAmount(req.Currency, req.TotalAmount); err != nil {
+		return response, &domain.ValidationError{
+			Message: "Validation failed",
+			Errors: []domain.FieldError{{
+				Field:   "total_amount",
+				Message: err.Error(),
+			}},
+		}
+	}
+	if req.MixedCurrency && !s.configInterface.GetMixedCurrencyEnabled() {
+		return response, domain.ErrMixedCurrency
+	}
+
+	now := time.Now()
+	calendar := s.configInterface.GetTransferCalendar()
+	transferDate := calendar.EarliestTransferDate(now)
+	if !req.TransferDate.IsZero() {
+		err = calendar.ValidateTransferDate(req.TransferDate, now)
+		if err != nil {
+			return response, &domain.ValidationError{
+				Message: "Validation failed",
+				Errors: []domain.FieldError{{
+					Field:   "transfer_date",
+					Message: err.Error(),
+				}},
+			}
+		}
+		transferDate = domain.TransferDay(req.TransferDate)
+	}
+
 	// set transaction
 	transactionGUID := uuid.New()
 	transaction := domain.Transaction{
 		ID:                transactionGUID,
+		AccountNumber:     req.AccountNumber,
 		TotalAmount:       req.TotalAmount,
 		TotalRecord:       req.TotalRecord,
+		Currency:          req.Currency,
 		FromAccount:       req.FromAccount,
 		Maker:             req.UserID,
-		TransferDate:      time.Now(),
-		CreatedAt:         time.Now(),
+		TransferDate:      transferDate,
+		CreatedAt:         now,
 		TransactionStatus: string(domain.WaitingApproval),
 	}
 
-	transactionDetails, err := parseCSVTransaction(*req.File)
+	var transactionDetails []domain.TransactionDetail
+	if template != nil {
+		transactionDetails, err = s.beneficiaryService.TemplateTransactionDetails(ctx, *template, req.Amounts)
+		if err != nil {
+			return response, err
+		}
+		if req.TotalRecord == 0 {
+			req = declareTemplateTotals(req, transactionDetails)
+			transaction.TotalAmount = req.TotalAmount
+			transaction.TotalRecord = req.TotalRecord
+		}
+	} else {
+		transactionDetails, err = s.parseTransactionFile(ctx, req, transferDate, now)
+		if err != nil {
+			return response, err
+		}
+	}
+
+	err = reconcileTransactionTotal(req, transactionDetails)
+	if err != nil {
+		return response, err
+	}
+	err = s.limitService.CheckTransaction(ctx, transaction.AccountNumber, transaction.Maker, transactionDetails, now)
+	if err != nil {
+		return response, err
+	}
+	transaction.CurrencyTotals = domain.SumByCurrency(transactionDetails)
+	transaction.MixedCurrency = len(transaction.CurrencyTotals) > 1
+
+	transaction.RequiredApprovals, err = s.transactionRepo.GetRequiredApprovals(ctx, transaction.AccountNumber, transaction.CurrencyTotals)
 	if err != nil {
-		return err
+		return response, err
+	}
+
+	transaction.Fingerprint, transaction.BeneficiaryFingerprint = fingerprintTransactionDetails(transactionDetails)
+	duplicate, err := s.transactionRepo.FindRecentDuplicate(ctx, transaction, now.Add(-s.configInterface.GetDuplicateCheckWindow()))
+	if err != nil {
+		return response, err
+	}
+	if duplicate != nil && duplicate.Identical && !req.ConfirmDuplicate {
+		return response, &domain.DuplicateTransactionError{TransactionID: duplicate.TransactionID}
 	}
 
 	for i := range transactionDetails {
 		transactionDetails[i].TransactionID = transactionGUID
+		if transactionDetails[i].TransferDate.IsZero() {
+			transactionDetails[i].TransferDate = transaction.TransferDate
+		} else {
+			transactionDetails[i].TransferDate = domain.TransferDay(transactionDetails[i].TransferDate)
+		}
+	}
+
+	hits := s.screenTransactionDetails(&transaction, transactionDetails)
+
+	err = s.transactionRepo.CreateTransaction(ctx, transaction, transactionDetails, hits)
+	if err != nil {
+		return response, err
 	}
 
-	return s.transactionRepo.CreateTransaction(ctx, transaction, transactionDetails)
+	response = domain.TransactionUploadResponse{
+		TransactionID: transactionGUID,
+		TotalRecord:   transaction.TotalRecord,
+		TotalAmount:   transaction.TotalAmount,
+		Currency:      transaction.Currency,
+		TransferDate:  transaction.TransferDate,
+		Message:       "Transaction created successfully",
 
+		CurrencyTotals: transaction.CurrencyTotals,
+	}
+	if transaction.TransactionStatus == string(domain.ComplianceReview) {
+		response.Message = "Transaction created and held for compliance review"
+	}
+	if duplicate != nil {
+		response.DuplicateOf = &duplicate.TransactionID
+		if duplicate.Identical {
+			response.Warning = "An identical batch was recently uploaded from this account"
+		} else {
+			response.Warning = "A batch to the same beneficiaries was recently uploaded from this account"
+		}
+	}
+	return response, nil
 }
 
-func parseCSVTransaction(file multipart.File) ([]domain.TransactionDetail, error) {
-	reader := csv.NewReader(file)
+// screenTransactionDetails gives every row its id and screens its beneficiary
+// against the watchlist. A batch with any hit is held for compliance review.
+func (s *TransactionService) screenTransactionDetails(transaction *domain.Transaction, details []domain.TransactionDetail) []domain.ScreeningHit {
+	var hits []domain.ScreeningHit
+	for i := range details {
+		details[i].ID = uuid.New()
+		hits = append(hits, s.screener.Screen(details[i])...)
+	}
+	if len(hits) > 0 {
+		transaction.TransactionStatus = string(domain.ComplianceReview)
+	}
+	return hits
+}
 
-	_, err := reader.Read()
-	if err != nil {
-		return nil, err
+// fingerprintTransactionDetails hashes the normalized rows of a batch. Rows are
+// sorted first so reordering a file does not change its fingerprint.
+func fingerprintTransactionDetails(details []domain.TransactionDetail) (fingerprint string, beneficiaryFingerprint string) {
+	var rows, beneficiaries []string
+	seen := map[string]bool{}
+	for _, detail := range details {
+		beneficiary := strings.ToUpper(strings.TrimSpace(detail.BankDest)) + "|" + strings.TrimSpace(detail.AccountIDDest)
+		rows = append(rows, fmt.Sprintf("%s|%s|%s", beneficiary, detail.Currency, detail.Amount))
+		if !seen[beneficiary] {
+			seen[beneficiary] = true
+			beneficiaries = append(beneficiaries, beneficiary)
+		}
 	}
+	sort.Strings(rows)
+	sort.Strings(beneficiaries)
 
-	var transactionDetails []domain.TransactionDetail
-	for {
-		line, err := reader.Read()
-		if err == io.EOF {
-			break
-		} else if err != nil {
-			return nil, err
+	rowHash := sha256.Sum256([]byte(strings.Join(rows, "\n")))
+	beneficiaryHash := sha256.Sum256([]byte(strings.Join(beneficiaries, "\n")))
+	return hex.EncodeToString(rowHash[:]), hex.EncodeToString(beneficiaryHash[:])
+}
+
+// reconcileTransactionTotal compares the totals declared by the maker against
+// the rows parsed from the uploaded file. total_amount covers the rows in the
+// batch currency, and every other currency must be declared in
+// currency_totals on a mixed currency batch.
+func reconcileTransactionTotal(req domain.TransactionUploadRequest, details []domain.TransactionDetail) error {
+	var fieldErrors []domain.FieldError
+
+	if req.TotalRecord != len(details) {
+		fieldErrors = append(fieldErrors, domain.FieldError{
+			Field:   "total_record",
+			Message: fmt.Sprintf("total_record %d does not match %d records in file", req.TotalRecord, len(details)),
+		})
+	}
+
+	currencyTotals := domain.SumByCurrency(details)
+	computed := map[string]domain.Money{}
+	for _, currencyTotal := range currencyTotals {
+		computed[currencyTotal.Currency] = currencyTotal.TotalAmount
+		if currencyTotal.Currency != req.Currency && !req.MixedCurrency {
+			fieldErrors = append(fieldErrors, domain.FieldError{
+				Field:   "currency",
+				Message: fmt.Sprintf("file has %s rows in a %s batch, set mixed_currency=true to upload more than one currency", currencyTotal.Currency, req.Currency),
+			})
 		}
+	}
 
-		amount, err := strconv.ParseFloat(line[3], 64)
-		if err != nil {
-			return nil, err
+	if total := computed[req.Currency]; req.TotalAmount != total {
+		fieldErrors = append(fieldErrors, domain.FieldError{
+			Field:   "total_amount",
+			Message: fmt.Sprintf("total_amount %s does not match %s %s in file", req.TotalAmount, total, req.Currency),
+		})
+	}
+
+	if req.MixedCurrency {
+		for _, currencyTotal := range currencyTotals {
+			currency, total := currencyTotal.Currency, currencyTotal.TotalAmount
+			if currency == req.Currency {
+				continue
+			}
+			declared, ok := req.CurrencyTotals[currency]
+			if !ok {
+				fieldErrors = append(fieldErrors, domain.FieldError{
+					Field:   "currency_totals",
+					Message: fmt.Sprintf("missing declared total for %s %s in file", total, currency),
+				})
+			} else if declared != total {
+				fieldErrors = append(fieldErrors, domain.FieldError{
+					Field:   "currency_totals",
+					Message: fmt.Sprintf("%s total %s does not match %s in file", currency, declared, total),
+				})
+			}
+		}
+	}
+	var declaredCurrencies []string
+	for currency := range req.CurrencyTotals {
+		declaredCurrencies = append(declaredCurrencies, currency)
+	}
+	sort.Strings(declaredCurrencies)
+	for _, currency := range declaredCurrencies {
+		if _, ok := computed[currency]; !ok && currency != req.Currency {
+			fieldErrors = append(fieldErrors, domain.FieldError{
+				Field:   "currency_totals",
+				Message: fmt.Sprintf("file has no %s rows", currency),
+			})
 		}
-		transactionDetail := domain.TransactionDetail{
-			BankDest:        line[0],
-			AccountIDDest:   line[1],
-			AccountNameDest: line[2],
-			Amount:          amount,
+	}
+
+	if len(fieldErrors) > 0 {
+		return &domain.ValidationError{
+			Message: "Transaction total does not match uploaded file",
+			Errors:  fieldErrors,
 		}
+	}
+
+	return nil
+}
 
-		transactionDetails = append(transactionDetails, transactionDetail)
+// parseTransactionFile reads the rows of an uploaded file. Rows dated on their
+// own must be valid transfer dates on or after the batch transferDate.
+func (s *TransactionService) parseTransactionFile(ctx context.Context, req domain.TransactionUploadRequest, transferDate time.Time, now time.Time) ([]domain.TransactionDetail, error) {
+	calendar := s.configInterface.GetTransferCalendar()
+	banks, err := s.bankService.GetDirectory(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	return transactionDetails, nil
+	transactionParser, err := parser.NewTransactionParser(req.FileName, req.ContentType, parser.Options{
+		ColumnAliases:    s.configInterface.GetCSVColumnAliases(),
+		FixedWidthLayout: s.configInterface.GetFixedWidthLayout(),
+		Currency:         req.Currency,
+		Banks:            banks,
+		// rows may be scheduled later than the batch, but never earlier
+		ValidateTransferDate: func(date time.Time) error {
+			if domain.TransferDay(date).Before(transferDate) {
+				return fmt.Errorf("transfer_date %s is before the batch transfer_date %s", date.Format(domain.TransferDateLayout), transferDate.Format(domain.TransferDateLayout))
+			}
+			return calendar.ValidateTransferDate(date, now)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transactionParser.Parse(*req.File)
+}
+
+// declareTemplateTotals fills in the totals of a template batch for a maker
+// who did not declare them, so only the currency rules are reconciled.
+func declareTemplateTotals(req domain.TransactionUploadRequest, details []domain.TransactionDetail) domain.TransactionUploadRequest {
+	req.TotalRecord = len(details)
+	req.TotalAmount = 0
+	req.CurrencyTotals = map[string]domain.Money{}
+	for _, currencyTotal := range domain.SumByCurrency(details) {
+		if currencyTotal.Currency == req.Currency {
+			req.TotalAmount = currencyTotal.TotalAmount
+		} else {
+			req.CurrencyTotals[currencyTotal.Currency] = currencyTotal.TotalAmount
+		}
+	}
+	return req
 }
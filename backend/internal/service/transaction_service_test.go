@@ -0,0 +1,351 @@
+package service
+
+import (
+	"batch-transaction/internal/config"
+	"batch-transaction/internal/database"
+	"batch-transaction/internal/domain"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	corporateA = "1111111111"
+	corporateB = "2222222222"
+)
+
+// fakeTransactionRepository keeps transactions in memory without scoping
+// them, and records the corporate every call is scoped to, so the tests can
+// check the service always hands the caller's corporate to the repository.
+// The scoping itself is covered by the repository tests.
+type fakeTransactionRepository struct {
+	transactions map[uuid.UUID]domain.Transaction
+	approvals    map[uuid.UUID][]domain.TransactionApproval
+
+	accountNumbers []string
+	listParams     []domain.TransactionListParam
+}
+
+func newFakeTransactionRepository(transactions ...domain.Transaction) *fakeTransactionRepository {
+	repo := &fakeTransactionRepository{
+		transactions: map[uuid.UUID]domain.Transaction{},
+		approvals:    map[uuid.UUID][]domain.TransactionApproval{},
+	}
+	for _, transaction := range transactions {
+		repo.transactions[transaction.ID] = transaction
+	}
+	return repo
+}
+
+func (r *fakeTransactionRepository) scope(accountNumber string) {
+	r.accountNumbers = append(r.accountNumbers, accountNumber)
+}
+
+func (r *fakeTransactionRepository) get(transactionID uuid.UUID) (domain.Transaction, error) {
+	transaction, ok := r.transactions[transactionID]
+	if !ok {
+		return domain.Transaction{}, domain.ErrTransactionNotFound
+	}
+	return transaction, nil
+}
+
+func (r *fakeTransactionRepository) GetTransactionSummary(ctx context.Context, accountNumber string) (domain.TransactionSummaryResult, error) {
+	r.scope(accountNumber)
+	return domain.TransactionSummaryResult{}, nil
+}
+
+func (r *fakeTransactionRepository) UpdateTransactionStatus(ctx context.Context, accountNumber string, event domain.TransactionEvent) error {
+	r.scope(accountNumber)
+	transaction, err := r.get(event.TransactionID)
+	if err != nil {
+		return err
+	}
+	transaction.TransactionStatus = string(event.NewStatus)
+	r.transactions[transaction.ID] = transaction
+	return nil
+}
+
+func (r *fakeTransactionRepository) GetTransactionByID(ctx context.Context, accountNumber string, transactionID uuid.UUID) (domain.Transaction, error) {
+	r.scope(accountNumber)
+	return r.get(transactionID)
+}
+
+func (r *fakeTransactionRepository) ApproveTransaction(ctx context.Context, accountNumber string, approval domain.TransactionApproval, event domain.TransactionEvent) (domain.ApprovalProgress, error) {
+	r.scope(accountNumber)
+	var progress domain.ApprovalProgress
+	transaction, err := r.get(approval.TransactionID)
+	if err != nil {
+		return progress, err
+	}
+	r.approvals[transaction.ID] = append(r.approvals[transaction.ID], approval)
+	progress.RequiredApprovals = transaction.RequiredApprovals
+	progress.Approvals = len(r.approvals[transaction.ID])
+	progress.Approved = progress.Approvals >= progress.RequiredApprovals
+	return progress, nil
+}
+
+func (r *fakeTransactionRepository) GetTransactionEvents(ctx context.Context, accountNumber string, transactionID uuid.UUID) ([]domain.TransactionEvent, error) {
+	r.scope(accountNumber)
+	return nil, nil
+}
+
+func (r *fakeTransactionRepository) GetTransactionApprovals(ctx context.Context, accountNumber string, transactionID uuid.UUID) ([]domain.TransactionApproval, error) {
+	r.scope(accountNumber)
+	return r.approvals[transactionID], nil
+}
+
+func (r *fakeTransactionRepository) GetRequiredApprovals(ctx context.Context, accountNumber string, totals []domain.CurrencyTotal) (int, error) {
+	r.scope(accountNumber)
+	return 1, nil
+}
+
+func (r *fakeTransactionRepository) GetTransactionList(ctx context.Context, param domain.TransactionListParam) ([]domain.Transaction, domain.Pagination, error) {
+	r.listParams = append(r.listParams, param)
+	return nil, domain.Pagination{}, nil
+}
+
+func (r *fakeTransactionRepository) GetTransactionDetailByTransactionID(ctx context.Context, accountNumber string, transactionID uuid.UUID) ([]domain.TransactionDetail, error) {
+	r.scope(accountNumber)
+	return nil, nil
+}
+
+func (r *fakeTransactionRepository) GetTransactionAccountNumber(ctx context.Context, transactionID uuid.UUID, status domain.TransactionStatus) (string, error) {
+	transaction, err := r.get(transactionID)
+	if err != nil || domain.TransactionStatus(transaction.TransactionStatus) != status {
+		return "", domain.ErrTransactionNotFound
+	}
+	return transaction.AccountNumber, nil
+}
+
+func (r *fakeTransactionRepository) CreateTransaction(ctx context.Context, trx domain.Transaction, trxDetails []domain.TransactionDetail, hits []domain.ScreeningHit) error {
+	r.scope(trx.AccountNumber)
+	r.transactions[trx.ID] = trx
+	return nil
+}
+
+func (r *fakeTransactionRepository) FindRecentDuplicate(ctx context.Context, trx domain.Transaction, since time.Time) (*domain.DuplicateTransaction, error) {
+	return nil, nil
+}
+
+func (r *fakeTransactionRepository) TransitionScheduledTransactions(ctx context.Context, from domain.TransactionStatus, to domain.TransactionStatus, before time.Time, event domain.TransactionEvent) ([]uuid.UUID, error) {
+	return nil, nil
+}
+
+func (r *fakeTransactionRepository) GetTransactionsByStatus(ctx context.Context, status domain.TransactionStatus) ([]domain.Transaction, error) {
+	return nil, nil
+}
+
+func (r *fakeTransactionRepository) UpdateTransactionDetailStatus(ctx context.Context, detail domain.TransactionDetail) error {
+	return nil
+}
+
+func (r *fakeTransactionRepository) FinishTransaction(ctx context.Context, accountNumber string, event domain.TransactionEvent) (domain.TransactionStatus, error) {
+	r.scope(accountNumber)
+	return "", nil
+}
+
+func (r *fakeTransactionRepository) GetRetryTransactionIDs(ctx context.Context, accountNumber string, transactionID uuid.UUID) ([]uuid.UUID, error) {
+	r.scope(accountNumber)
+	return nil, nil
+}
+
+func (r *fakeTransactionRepository) GetPendingInquiries(ctx context.Context, limit int) ([]domain.TransactionDetail, error) {
+	return nil, nil
+}
+
+func (r *fakeTransactionRepository) UpdateInquiryResult(ctx context.Context, detail domain.TransactionDetail) error {
+	return nil
+}
+
+func (r *fakeTransactionRepository) GetScreeningHits(ctx context.Context, accountNumber string, transactionID uuid.UUID) ([]domain.ScreeningHit, error) {
+	r.scope(accountNumber)
+	return nil, nil
+}
+
+// assertScopedTo fails unless every call was scoped to accountNumber.
+func (r *fakeTransactionRepository) assertScopedTo(t *testing.T, accountNumber string) {
+	t.Helper()
+	if len(r.accountNumbers) == 0 {
+		t.Fatal("repository was not called")
+	}
+	for i, got := range r.accountNumbers {
+		if got != accountNumber {
+			t.Errorf("call %d scoped to %q, want %q", i, got, accountNumber)
+		}
+	}
+}
+
+// fakeUserRepository only answers the approver lookup of a transaction
+// detail.
+type fakeUserRepository struct {
+	domain.UserRepository
+}
+
+func (r *fakeUserRepository) GetUsersByAccountNumber(ctx context.Context, accountNumber string, role domain.Role) ([]domain.User, error) {
+	return nil, nil
+}
+
+// fakeConfig only answers the settings read by an approval, which needs no
+// OTP and no account name inquiry.
+type fakeConfig struct {
+	config.ConfigInterface
+}
+
+func (c *fakeConfig) GetTransferCalendar() domain.TransferCalendar {
+	return domain.NewTransferCalendar(24*time.Hour, nil, time.UTC)
+}
+
+func (c *fakeConfig) GetInquiryBlockMismatch() bool {
+	return false
+}
+
+func (c *fakeConfig) GetApprovalOTPMinAmounts() map[string]domain.Money {
+	return map[string]domain.Money{"IDR": 1 << 62}
+}
+
+func newTestTransactionService(repo *fakeTransactionRepository) *TransactionService {
+	return NewTransactionService(repo, &fakeUserRepository{}, nil, nil, nil, nil, nil, nil, database.RedisClient{}, &fakeConfig{})
+}
+
+func newTestTransaction(accountNumber string, status domain.TransactionStatus) domain.Transaction {
+	return domain.Transaction{
+		ID:                uuid.New(),
+		AccountNumber:     accountNumber,
+		TotalAmount:       100000,
+		TotalRecord:       1,
+		Currency:          "IDR",
+		Maker:             "maker-" + accountNumber,
+		TransferDate:      time.Now().AddDate(0, 1, 0),
+		TransactionStatus: string(status),
+		RequiredApprovals: 1,
+	}
+}
+
+func TestGetTransactionSummaryIsScopedToCaller(t *testing.T) {
+	repo := newFakeTransactionRepository()
+	service := newTestTransactionService(repo)
+
+	_, err := service.GetTransactionSummary(context.Background(), corporateA)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	repo.assertScopedTo(t, corporateA)
+}
+
+func TestGetTransactionListIsScopedToCaller(t *testing.T) {
+	for _, role := range []domain.Role{domain.Maker, domain.Approver, domain.Admin} {
+		repo := newFakeTransactionRepository()
+		service := newTestTransactionService(repo)
+
+		_, _, err := service.GetTransactionList(context.Background(), domain.TransactionListParam{
+			AccountNumber: corporateA,
+			Role:          role,
+			AllCorporates: true,
+		})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", role, err)
+		}
+		param := repo.listParams[0]
+		if param.AllCorporates || param.AccountNumber != corporateA {
+			t.Errorf("%s: listed corporate %q with AllCorporates %v, want only %q", role, param.AccountNumber, param.AllCorporates, corporateA)
+		}
+	}
+}
+
+func TestGetTransactionListComplianceOnlyListsHeldBatches(t *testing.T) {
+	repo := newFakeTransactionRepository()
+	service := newTestTransactionService(repo)
+
+	_, _, err := service.GetTransactionList(context.Background(), domain.TransactionListParam{
+		Role:         domain.Compliance,
+		StatusFilter: []domain.TransactionStatus{domain.WaitingApproval, domain.Approved},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	param := repo.listParams[0]
+	if !param.AllCorporates {
+		t.Error("got AllCorporates false, want true")
+	}
+	if len(param.StatusFilter) != 1 || param.StatusFilter[0] != domain.ComplianceReview {
+		t.Errorf("got status filter %v, want only %s", param.StatusFilter, domain.ComplianceReview)
+	}
+}
+
+func TestGetTransactionDetailIsScopedToCaller(t *testing.T) {
+	transaction := newTestTransaction(corporateB, domain.WaitingApproval)
+	repo := newFakeTransactionRepository(transaction)
+	service := newTestTransactionService(repo)
+
+	_, err := service.GetTransactionDetail(context.Background(), corporateA, domain.Approver, transaction.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	repo.assertScopedTo(t, corporateA)
+}
+
+func TestGetTransactionHistoryIsScopedToCaller(t *testing.T) {
+	transaction := newTestTransaction(corporateB, domain.WaitingApproval)
+	repo := newFakeTransactionRepository(transaction)
+	service := newTestTransactionService(repo)
+
+	_, err := service.GetTransactionHistory(context.Background(), corporateA, domain.Maker, transaction.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	repo.assertScopedTo(t, corporateA)
+}
+
+func TestApproveTransactionIsScopedToCaller(t *testing.T) {
+	transaction := newTestTransaction(corporateB, domain.WaitingApproval)
+	repo := newFakeTransactionRepository(transaction)
+	service := newTestTransactionService(repo)
+
+	_, err := service.UpdateTransaction(context.Background(), domain.UpdateTransactionRequest{
+		Status:        string(domain.Approved),
+		TransactionID: transaction.ID,
+		UserID:        "approver-" + corporateA,
+		Role:          domain.Approver,
+		AccountNumber: corporateA,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	repo.assertScopedTo(t, corporateA)
+}
+
+func TestComplianceIsScopedToCorporateOfHeldBatch(t *testing.T) {
+	held := newTestTransaction(corporateB, domain.ComplianceReview)
+	repo := newFakeTransactionRepository(held)
+	service := newTestTransactionService(repo)
+
+	_, err := service.GetTransactionDetail(context.Background(), "", domain.Compliance, held.ID)
+	if err != nil {
+		t.Fatalf("detail: unexpected error: %v", err)
+	}
+	_, err = service.GetTransactionHistory(context.Background(), "", domain.Compliance, held.ID)
+	if err != nil {
+		t.Fatalf("history: unexpected error: %v", err)
+	}
+	repo.assertScopedTo(t, corporateB)
+}
+
+func TestComplianceCannotSeeBatchNotHeld(t *testing.T) {
+	waiting := newTestTransaction(corporateB, domain.WaitingApproval)
+	repo := newFakeTransactionRepository(waiting)
+	service := newTestTransactionService(repo)
+
+	_, err := service.GetTransactionDetail(context.Background(), "", domain.Compliance, waiting.ID)
+	if err != domain.ErrTransactionNotFound {
+		t.Errorf("detail: got %v, want %v", err, domain.ErrTransactionNotFound)
+	}
+	_, err = service.GetTransactionHistory(context.Background(), "", domain.Compliance, waiting.ID)
+	if err != domain.ErrTransactionNotFound {
+		t.Errorf("history: got %v, want %v", err, domain.ErrTransactionNotFound)
+	}
+	if len(repo.accountNumbers) != 0 {
+		t.Errorf("got scoped calls %v, want none", repo.accountNumbers)
+	}
+}
@@ -40,9 +40,9 @@ func (s *UserService) Register(ctx context.Context, userReq domain.UserRegisterR
 		return err
 	}
 
-	err = s.otpService.ValidateOTP(userReq.Email, userReq.OtpCode)
+	err = s.otpService.ValidateOTP(ctx, userReq.Email, userReq.OtpCode)
 	if err != nil {
-		return domain.ErrInvalidOTP
+		return err
 	}
 
 	user := domain.User{
@@ -56,14 +56,59 @@ func (s *UserService) Register(ctx context.Context, userReq domain.UserRegisterR
 		Email:         userReq.Email,
 	}
 
+	// registering starts a new corporate, joining one needs its admin
 	isExist, err := s.userRepo.IsExistUser(ctx, user)
-	if err != nil || isExist {
+	if err != nil {
 		return err
 	}
+	if isExist {
+		return domain.ErrorUserAlreadyExist
+	}
 
 	return s.userRepo.CreateUser(ctx, user)
 }
 
+// CreateCorporateUser adds a maker or approver to the admin's corporate.
+func (s *UserService) CreateCorporateUser(ctx context.Context, req domain.CorporateUserRequest) error {
+	if req.AdminRole != domain.Admin {
+		return domain.ErrRoleNotAllowed
+	}
+
+	err := domain.ValidateCorporateUserRequest(&req)
+	if err != nil {
+		return err
+	}
+
+	admin, err := s.userRepo.GetUserByUserID(ctx, req.AdminUserID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.userRepo.GetUserByUserID(ctx, req.UserID)
+	if err == nil {
+		return domain.ErrorUserAlreadyExist
+	}
+	if err != domain.ErrorUserNotFound {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	return s.userRepo.CreateUser(ctx, domain.User{
+		AccountNumber: req.AccountNumber,
+		AccountName:   admin.AccountName,
+		UserID:        req.UserID,
+		UserName:      req.UserName,
+		Password:      string(hashedPassword),
+		Role:          req.Role,
+		PhoneNumber:   req.PhoneNumber,
+		Email:         req.Email,
+	})
+}
+
 func (s *UserService) Login(ctx context.Context, user domain.UserLogin) (result domain.LoginResponse, err error) {
 	err = domain.ValidateLogin(&user)
 	if err != nil {
@@ -77,6 +122,10 @@ func (s *UserService) Login(ctx context.Context, user domain.UserLogin) (result
 	if err != nil {
 		return result, err
 	}
+	// a user can only sign in to the corporate account they belong to
+	if userData.AccountNumber != user.AccountNumber {
+		return result, domain.ErrorUserNotFound
+	}
 
 	err = s.passwordComparer.ComparePassword(user.Password, userData.Password)
 	if err != nil {
@@ -89,8 +138,9 @@ func (s *UserService) Login(ctx context.Context, user domain.UserLogin) (result
 	}
 
 	token, err := s.jwtService.SignJWT(domain.User{
-		UserID: userData.UserID,
-		Role:   userData.Role,
+		UserID:        userData.UserID,
+		Role:          userData.Role,
+		AccountNumber: userData.AccountNumber,
 	}, []byte(s.configInterface.GetSecretKey()))
 	if err != nil {
 		return result, err
@@ -0,0 +1,162 @@
+package worker
+
+import (
+	"batch-transaction/internal/config"
+	"batch-transaction/internal/domain"
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	null "gopkg.in/guregu/null.v4"
+)
+
+// actor is recorded as the user behind status changes made by the worker.
+const actor = "worker"
+
+// Worker executes the details of processing batches through a
+// domain.TransferExecutor, running at most EXECUTION_WORKERS transfers at a
+// time across all batches. Only pending details are sent, so a batch that
+// was interrupted by a restart is resumed where it stopped.
+type Worker struct {
+	transactionRepo domain.TransactionRepository
+	executor        domain.TransferExecutor
+	configInterface config.ConfigInterface
+	log             *log.Logger
+
+	slots   chan struct{}
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	running map[uuid.UUID]bool
+}
+
+func NewWorker(transactionRepo domain.TransactionRepository, executor domain.TransferExecutor, configInterface config.ConfigInterface, log *log.Logger) *Worker {
+	return &Worker{
+		transactionRepo: transactionRepo,
+		executor:        executor,
+		configInterface: configInterface,
+		log:             log,
+		slots:           make(chan struct{}, configInterface.GetExecutionWorkers()),
+		running:         map[uuid.UUID]bool{},
+	}
+}
+
+// Run picks up processing batches every SCHEDULER_INTERVAL until ctx is done,
+// then waits for the transfers in flight.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.configInterface.GetSchedulerInterval())
+	defer ticker.Stop()
+
+	for {
+		w.Poll(ctx)
+
+		select {
+		case <-ctx.Done():
+			w.wg.Wait()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll starts executing every processing batch that is not already running.
+func (w *Worker) Poll(ctx context.Context) {
+	transactions, err := w.transactionRepo.GetTransactionsByStatus(ctx, domain.Processing)
+	if err != nil {
+		w.log.Println("worker: get processing transactions:", err)
+		return
+	}
+
+	for _, transaction := range transactions {
+		w.mu.Lock()
+		if w.running[transaction.ID] {
+			w.mu.Unlock()
+			continue
+		}
+		w.running[transaction.ID] = true
+		w.mu.Unlock()
+
+		w.wg.Add(1)
+		go func(transaction domain.Transaction) {
+			defer w.wg.Done()
+			defer func() {
+				w.mu.Lock()
+				delete(w.running, transaction.ID)
+				w.mu.Unlock()
+			}()
+			w.execute(ctx, transaction)
+		}(transaction)
+	}
+}
+
+func (w *Worker) execute(ctx context.Context, transaction domain.Transaction) {
+	details, err := w.transactionRepo.GetTransactionDetailByTransactionID(ctx, transaction.AccountNumber, transaction.ID)
+	if err != nil {
+		w.log.Println("worker: get details of transaction", transaction.ID, ":", err)
+		return
+	}
+
+	// rows dated later than the batch wait for their own transfer date
+	today := w.configInterface.GetTransferCalendar().Today(time.Now())
+
+	var wg sync.WaitGroup
+	for _, detail := range details {
+		if detail.Status != domain.DetailPending || domain.TransferDay(detail.TransferDate).After(today) {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case w.slots <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(detail domain.TransactionDetail) {
+			defer wg.Done()
+			defer func() { <-w.slots }()
+			w.transfer(ctx, transaction, detail)
+		}(detail)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	status, err := w.transactionRepo.FinishTransaction(ctx, transaction.AccountNumber, domain.TransactionEvent{
+		TransactionID: transaction.ID,
+		Actor:         actor,
+		ActorRole:     domain.System,
+		CreatedAt:     time.Now(),
+	})
+	if err != nil {
+		w.log.Println("worker: finish transaction", transaction.ID, ":", err)
+		return
+	}
+	if status != domain.Processing {
+		w.log.Println("worker: transaction", transaction.ID, "finished as", status)
+	}
+}
+
+func (w *Worker) transfer(ctx context.Context, transaction domain.Transaction, detail domain.TransactionDetail) {
+	err := w.executor.Transfer(ctx, transaction.FromAccount, detail)
+	if ctx.Err() != nil {
+		// shutting down, leave the detail pending so it is resumed
+		return
+	}
+
+	detail.Status = domain.DetailSuccess
+	if err != nil {
+		detail.Status = domain.DetailFailed
+		detail.FailureReason = null.StringFrom(err.Error())
+	}
+	detail.ExecutedAt = null.TimeFrom(time.Now())
+
+	err = w.transactionRepo.UpdateTransactionDetailStatus(ctx, detail)
+	if err != nil {
+		w.log.Println("worker: update detail", detail.ID, ":", err)
+	}
+}
@@ -5,11 +5,20 @@ import (
 	"batch-transaction/internal/auth"
 	"batch-transaction/internal/config"
 	"batch-transaction/internal/database"
+	"batch-transaction/internal/domain"
+	"batch-transaction/internal/executor"
 	"batch-transaction/internal/handler"
+	"batch-transaction/internal/inquiry"
+	"batch-transaction/internal/otp"
 	"batch-transaction/internal/repository"
+	"batch-transaction/internal/scheduler"
+	"batch-transaction/internal/screening"
 	"batch-transaction/internal/service"
+	"batch-transaction/internal/worker"
+	"context"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/redis/go-redis/v9"
@@ -37,20 +46,77 @@ func main() {
 	passwordCompare := auth.PasswordComparerImpl{}
 	jwtService := auth.NewJWTServiceImpl()
 
-	otpService := service.NewOTPService(*redisClient)
+	var otpSender domain.OTPSender
+	switch config.GetOTPChannel() {
+	case "email":
+		otpSender = otp.NewSMTPSender(config.GetSMTPAddr(), config.GetSMTPUsername(), config.GetSMTPPassword(), config.GetSMTPFrom())
+	case "sms":
+		otpSender = otp.NewSMSSender(config.GetSMSGatewayURL(), config.GetSMSGatewayAPIKey())
+	case "log":
+		// codes in a log are only acceptable while developing
+		if !config.GetOTPDevMode() {
+			log.Fatal("OTP_CHANNEL=log needs OTP_DEV_MODE=true")
+		}
+		otpSender, err = otp.NewLogSender(config.GetOTPLogFile())
+		if err != nil {
+			log.Fatal("open otp log: ", err)
+		}
+	case "":
+		log.Fatal("OTP_CHANNEL is not set, use email, sms or log")
+	default:
+		log.Fatal("unknown OTP_CHANNEL: ", config.GetOTPChannel())
+	}
+	otpService := service.NewOTPService(*redisClient, otpSender, config)
 
 	userRepo := repository.NewUserRepository(db)
 	userService := service.NewUserService(passwordCompare, jwtService, config, *otpService, userRepo)
 
+	linkedAccountRepo := repository.NewLinkedAccountRepository(db)
+	accountService := service.NewAccountService(linkedAccountRepo)
+
+	bankRepo := repository.NewBankRepository(db)
+	bankService := service.NewBankService(bankRepo)
+	err = bankService.SeedBanks(context.Background(), config.GetBankDirectoryFile())
+	if err != nil {
+		log.Println("seed bank directory:", err)
+	}
+
+	watchlist, err := screening.LoadWatchlist(config.GetWatchlistFile())
+	if err != nil {
+		log.Fatal("load watchlist: ", err)
+	}
+	screener := screening.NewWatchlistScreener(watchlist, config.GetScreeningThreshold())
+
+	limitRepo := repository.NewLimitRepository(db)
+	limitService := service.NewLimitService(limitRepo, userRepo)
+
 	transactionRepo := repository.NewTransactionRepository(db)
-	transactionService := service.NewTransactionService(transactionRepo)
+	beneficiaryRepo := repository.NewBeneficiaryRepository(db)
+	beneficiaryService := service.NewBeneficiaryService(beneficiaryRepo, accountService, bankService)
+
+	transactionService := service.NewTransactionService(transactionRepo, userRepo, accountService, beneficiaryService, bankService, screener, limitService, otpService, *redisClient, config)
+
+	transactionScheduler := scheduler.NewScheduler(transactionRepo, config, log.Default())
+	go transactionScheduler.Run(context.Background())
+
+	transferExecutor := executor.NewSimulatedExecutor(200 * time.Millisecond)
+	executionWorker := worker.NewWorker(transactionRepo, transferExecutor, config, log.Default())
+	go executionWorker.Run(context.Background())
+
+	accountInquiry := inquiry.NewFakeInquiry(50 * time.Millisecond)
+	inquirer := inquiry.NewInquirer(transactionRepo, accountInquiry, config, log.Default())
+	go inquirer.Run(context.Background())
 
 	healthHandler := handler.NewHealthHandler()
 	userHandler := handler.NewUserHandler(userService)
 	otpHandler := handler.NewOTPHandler(otpService)
 	transactionHandler := handler.NewTransactionHandler(transactionService)
+	accountHandler := handler.NewAccountHandler(accountService)
+	beneficiaryHandler := handler.NewBeneficiaryHandler(beneficiaryService)
+	bankHandler := handler.NewBankHandler(bankService)
+	limitHandler := handler.NewLimitHandler(limitService)
 
-	r := internal.NewRouter(healthHandler, otpHandler, userHandler, transactionHandler, jwtService, config)
+	r := internal.NewRouter(healthHandler, otpHandler, userHandler, transactionHandler, accountHandler, beneficiaryHandler, bankHandler, limitHandler, jwtService, config)
 	port := "1323"
 	log.Println("Server running on port", port)
 	log.Fatal(http.ListenAndServe(":"+port, r))
@@ -71,7 +71,10 @@ const RegisterForm: FC = () => {
         headers: {
           "Content-Type": "application/json",
         },
-        body: JSON.stringify({ email: formData.email }),
+        body: JSON.stringify({
+          email: formData.email,
+          ...(formData.phone_number && { phone_number: formData.phone_number }),
+        }),
       });
 
       const data = await response.json();
@@ -81,7 +84,8 @@ const RegisterForm: FC = () => {
         return;
       }
 
-      setOtpResponse(data.otp);
+      // the code is only returned when the backend runs with OTP_DEV_MODE
+      setOtpResponse(data.otp ? `OTP code: ${data.otp}` : data.message);
     } catch (error) {
       console.error(error);
     }
@@ -333,7 +337,7 @@ const RegisterForm: FC = () => {
             </button>
           </div>
           {otpResponse && (
-            <div className="text-green-500">OTP code: {otpResponse}</div>
+            <div className="text-green-500">{otpResponse}</div>
           )}
 
           {errors.otp_code && (
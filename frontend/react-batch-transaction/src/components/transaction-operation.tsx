@@ -49,16 +49,39 @@ const TransactionOperation: React.FC<TransactionOperationProps> = React.memo(({i
         const confirmed = window.confirm('Are you sure you want to approve this transaction?');
         if (confirmed) {
           try {
+            // large batches need a one-time code sent to the approver
+            const otpResponse = await fetch(`${API_URL}/api/transactions/${item.ID}/otp`, {
+              method: 'POST',
+              headers: {
+                Authorization: `Bearer ${token}`,
+              },
+            });
+            const otpData = await otpResponse.json();
+            if (!otpResponse.ok) {
+              window.alert(otpData.message);
+              return;
+            }
+            let otpCode: string | null = '';
+            if (otpData.otp_required) {
+              // the code is only returned when the backend runs with OTP_DEV_MODE
+              otpCode = window.prompt('Please enter the OTP code sent to you to approve this transaction', otpData.otp || '');
+              if (!otpCode || otpCode.trim() === '') {
+                return;
+              }
+            }
+
             const response = await fetch(`${API_URL}/api/transactions/${item.ID}`, {
               method: 'PATCH',
               headers: {
                 'Content-Type': 'application/json',
                 Authorization: `Bearer ${token}`,
               },
-              body: JSON.stringify({ status: 'approved' }),
+              body: JSON.stringify({ status: 'approved', otp_code: otpCode.trim() }),
             });
             if (!response.ok) {
-              throw new Error('Network response was not ok');
+              const data = await response.json();
+              window.alert(data.errors?.[0]?.message || data.message);
+              return;
             }
             onTransactionUpdate();
           } catch (error) {
@@ -68,8 +91,8 @@ const TransactionOperation: React.FC<TransactionOperationProps> = React.memo(({i
     }, [token, onTransactionUpdate]);
 
     const handleReject = useCallback(async () => {
-        const confirmed = window.confirm('Are you sure you want to reject this transaction?');
-        if (confirmed) {
+        const reason = window.prompt('Please enter the reason for rejecting this transaction');
+        if (reason && reason.trim() !== '') {
           try {
             const response = await fetch(`${API_URL}/api/transactions/${item.ID}`, {
               method: 'PATCH',
@@ -77,7 +100,7 @@ const TransactionOperation: React.FC<TransactionOperationProps> = React.memo(({i
                 'Content-Type': 'application/json',
                 Authorization: `Bearer ${token}`,
               },
-              body: JSON.stringify({ status: 'rejected' }),
+              body: JSON.stringify({ status: 'rejected', reason: reason.trim() }),
             });
             if (!response.ok) {
               throw new Error('Network response was not ok');